@@ -0,0 +1,151 @@
+package pathmatcher
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// routeSets are the route sets benchmarked against the requests replayed from
+// testdata/requests/<file>.jsonl.
+var routeSets = []struct {
+	name, file string
+	routes     []route
+}{
+	{"GitHub", "github", githubAPI},
+	{"Parse", "parse", parseAPI},
+	{"GPlus", "gplus", gplusAPI},
+	{"Static", "static", staticRoutes},
+}
+
+// loadRequests reads the requests to replay from testdata/requests.
+func loadRequests(tb testing.TB, file string) []route {
+	tb.Helper()
+	f, err := os.Open(filepath.Join("testdata", "requests", file+".jsonl"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer f.Close()
+
+	var requests []route
+	dec := json.NewDecoder(f)
+	for dec.More() {
+		var r struct{ Method, Path string }
+		if err := dec.Decode(&r); err != nil {
+			tb.Fatal(err)
+		}
+		requests = append(requests, route{r.Method, r.Path})
+	}
+	if len(requests) == 0 {
+		tb.Fatalf("no requests in %s", file)
+	}
+	return requests
+}
+
+func loadMatcher(routes []route) *Matcher[string] {
+	m := NewMatcher[string]()
+	seen := make(map[string]bool)
+	for _, r := range routes {
+		if !seen[r.path] {
+			seen[r.path] = true
+			m.Add(r.path, r.path)
+		}
+	}
+	return m
+}
+
+func loadHttpMatcher(routes []route) *HttpMatcher[string] {
+	m := NewHttpMatcher[string]()
+	for _, r := range routes {
+		m.Add(r.method, r.path, r.path)
+	}
+	return m
+}
+
+// TestRouteSets checks that the replayed requests mostly hit their route set,
+// so that the benchmarks measure matches rather than misses.
+func TestRouteSets(t *testing.T) {
+	for _, set := range routeSets {
+		m := loadHttpMatcher(set.routes)
+		requests := loadRequests(t, set.file)
+		var hits int
+		for _, r := range requests {
+			if match, _, _, _ := m.Find(r.method, r.path); match != "" {
+				hits++
+			}
+		}
+		if hits < len(requests)*9/10 {
+			t.Errorf("%s: only %d of %d requests match", set.name, hits, len(requests))
+		}
+	}
+}
+
+func BenchmarkMatcherFind(b *testing.B) {
+	for _, set := range routeSets {
+		m := loadMatcher(set.routes)
+		requests := loadRequests(b, set.file)
+		b.Run(set.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				m.Find(requests[i%len(requests)].path)
+			}
+		})
+	}
+}
+
+func BenchmarkHttpMatcherFind(b *testing.B) {
+	for _, set := range routeSets {
+		m := loadHttpMatcher(set.routes)
+		requests := loadRequests(b, set.file)
+		b.Run(set.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r := requests[i%len(requests)]
+				m.Find(r.method, r.path)
+			}
+		})
+	}
+}
+
+func BenchmarkHttpMatcherAllowed(b *testing.B) {
+	for _, set := range routeSets {
+		m := loadHttpMatcher(set.routes)
+		requests := loadRequests(b, set.file)
+		b.Run(set.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				m.Allowed(requests[i%len(requests)].path)
+			}
+		})
+	}
+}
+
+// BenchmarkHttpMatcherStaticOnly replays only the requests without params, to
+// isolate the exact-match lookup of static patterns. The Map runs use the map
+// of static patterns, the Tree runs walk the tree without it.
+func BenchmarkHttpMatcherStaticOnly(b *testing.B) {
+	for _, set := range routeSets {
+		m := loadHttpMatcher(set.routes)
+		var requests []route
+		for _, r := range set.routes {
+			if !strings.ContainsAny(r.path, ":*") {
+				requests = append(requests, r)
+			}
+		}
+		if len(requests) == 0 {
+			continue
+		}
+		run := func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r := requests[i%len(requests)]
+				m.Find(r.method, r.path)
+			}
+		}
+		b.Run(set.name+"/Map", run)
+		m.static = nil
+		b.Run(set.name+"/Tree", run)
+	}
+}
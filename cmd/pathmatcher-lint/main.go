@@ -0,0 +1,61 @@
+// Command pathmatcher-lint checks a route table for problems that would make
+// pathmatcher panic or leave routes unreachable.
+//
+// Usage:
+//
+//	pathmatcher-lint [file ...]
+//
+// Each file lists one route per line, either as "METHOD /path" or just
+// "/path". Blank lines and lines starting with '#' are ignored. Without file
+// arguments the routes are read from standard input. All problems are reported
+// at once, and the exit status is 1 if any were found.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/infogulch/pathmatcher"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: pathmatcher-lint [file ...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	status := 0
+	if flag.NArg() == 0 {
+		status = lint("<stdin>", os.Stdin)
+	}
+	for _, name := range flag.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 2
+			continue
+		}
+		status = max(status, lint(name, f))
+		f.Close()
+	}
+	os.Exit(status)
+}
+
+func lint(name string, r io.Reader) int {
+	entries, err := pathmatcher.ParseLintEntries(r)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		return 2
+	}
+	issues := pathmatcher.Lint(entries)
+	for _, issue := range issues {
+		fmt.Printf("%s:%d: %s: %s\n", name, issue.Entry.Line, issue.Kind, issue.Message)
+	}
+	if len(issues) > 0 {
+		return 1
+	}
+	return 0
+}
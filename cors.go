@@ -0,0 +1,131 @@
+package pathmatcher
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS configures cross-origin resource sharing for the routes of a group,
+// see RouteGroup.CORS.
+type CORS struct {
+	// AllowedOrigins lists the origins allowed to make requests, such as
+	// "https://example.com". "*" allows any origin.
+	AllowedOrigins []string
+
+	// AllowedHeaders lists the request headers allowed in actual requests.
+	// "*" allows any header the preflight request asks for.
+	AllowedHeaders []string
+
+	// ExposedHeaders lists the response headers the client may read.
+	ExposedHeaders []string
+
+	// AllowCredentials allows requests with cookies or authorization. The
+	// origin is then echoed instead of answering "*".
+	AllowCredentials bool
+
+	// MaxAge is how long a preflight response may be cached. Zero leaves it
+	// up to the client.
+	MaxAge time.Duration
+}
+
+// CORS enables CORS for the routes added to the group afterwards, including
+// those of its subgroups. Their responses to allowed origins carry the CORS
+// headers, and the router answers preflight requests for them with 204 No
+// Content and Access-Control-Allow-Methods from HttpMatcher.Allowed. The route
+// of a preflight request is looked up like FindRequest does for the requested
+// method, so a GET route with CORS covers HEAD requests with the default
+// MethodFallbacks, and a route of a host matcher, see HttpMatcher.Host, shadows
+// the group's route without enabling CORS. Like Use, CORS panics once routes
+// or subgroups were added to the group.
+func (g *RouteGroup) CORS(c CORS) {
+	if g.sealed {
+		panic("CORS must be configured before routes and groups under prefix '" + g.prefix + "'")
+	}
+	g.cors = &c
+}
+
+// allowOrigin returns the value of Access-Control-Allow-Origin for origin, or
+// the empty string if origin is not allowed.
+func (c *CORS) allowOrigin(origin string) string {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" {
+			if c.AllowCredentials {
+				return origin
+			}
+			return "*"
+		}
+		if strings.EqualFold(allowed, origin) {
+			return origin
+		}
+	}
+	return ""
+}
+
+// setHeaders sets the headers common to preflight and actual responses and
+// reports whether origin is allowed.
+func (c *CORS) setHeaders(header http.Header, origin string) bool {
+	header.Add("Vary", "Origin")
+	allow := c.allowOrigin(origin)
+	if allow == "" {
+		return false
+	}
+	header.Set("Access-Control-Allow-Origin", allow)
+	if c.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	return true
+}
+
+// wrap adds the CORS headers to the responses of handler.
+func (c *CORS) wrap(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" && c.setHeaders(w.Header(), origin) {
+			if len(c.ExposedHeaders) > 0 {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(c.ExposedHeaders, ", "))
+			}
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// preflight answers the preflight request r if the route it asks for has CORS
+// enabled, and reports whether it did.
+func (rt *Router) preflight(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	method := r.Header.Get("Access-Control-Request-Method")
+	if origin == "" || method == "" || len(rt.cors) == 0 {
+		return false
+	}
+	// Look up the requested method like FindRequest would, including hosts
+	// and MethodFallbacks, but without counting the preflight in Stats
+	actual := *r
+	actual.Method = method
+	res, hm := rt.Matcher.resolve(&actual)
+	if !res.Found() || hm != rt.Matcher {
+		// Routes of host matchers are not added by groups, so have no CORS
+		return false
+	}
+	c := rt.cors[res.Method][res.Match]
+	if c == nil {
+		return false
+	}
+
+	header := w.Header()
+	if c.setHeaders(header, origin) {
+		header.Set("Access-Control-Allow-Methods", hm.Allowed(rt.matchPath(r)))
+		if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+			if contains(c.AllowedHeaders, "*") {
+				header.Set("Access-Control-Allow-Headers", requested)
+			} else if len(c.AllowedHeaders) > 0 {
+				header.Set("Access-Control-Allow-Headers", strings.Join(c.AllowedHeaders, ", "))
+			}
+		}
+		if c.MaxAge > 0 {
+			header.Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge/time.Second)))
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
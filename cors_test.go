@@ -0,0 +1,129 @@
+package pathmatcher
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORS(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) }
+	deny := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "denied", http.StatusUnauthorized)
+		})
+	}
+
+	rt := NewRouter()
+	rt.GET("/private", ok)
+	api := rt.Group("/api")
+	api.CORS(CORS{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedHeaders: []string{"Content-Type", "Authorization"},
+		ExposedHeaders: []string{"X-Total"},
+		MaxAge:         10 * time.Minute,
+	})
+	api.GET("/items", ok)
+	api.PUT("/items", ok)
+	api.Handle("DELETE", "/items", http.HandlerFunc(ok), deny)
+	public := rt.Group("/public")
+	public.CORS(CORS{AllowedOrigins: []string{"*"}, AllowedHeaders: []string{"*"}, AllowCredentials: true})
+	public.GET("/feed", ok)
+	rt.Matcher.Host("api.example.com").GET("/api/items", http.HandlerFunc(ok))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		header http.Header
+		code   int
+		want   map[string]string
+	}{
+		{"preflight", "OPTIONS", "/api/items", http.Header{
+			"Origin":                         {"https://app.example.com"},
+			"Access-Control-Request-Method":  {"PUT"},
+			"Access-Control-Request-Headers": {"content-type"},
+		}, http.StatusNoContent, map[string]string{
+			"Access-Control-Allow-Origin":  "https://app.example.com",
+			"Access-Control-Allow-Methods": "DELETE, GET, OPTIONS, PUT",
+			"Access-Control-Allow-Headers": "Content-Type, Authorization",
+			"Access-Control-Max-Age":       "600",
+			"Vary":                         "Origin",
+		}},
+		{"preflight for fallback method", "OPTIONS", "/api/items", http.Header{
+			"Origin":                        {"https://app.example.com"},
+			"Access-Control-Request-Method": {"HEAD"},
+		}, http.StatusNoContent, map[string]string{
+			"Access-Control-Allow-Origin":  "https://app.example.com",
+			"Access-Control-Allow-Methods": "DELETE, GET, OPTIONS, PUT",
+		}},
+		{"preflight for host route", "OPTIONS", "http://api.example.com/api/items", http.Header{
+			"Origin":                        {"https://app.example.com"},
+			"Access-Control-Request-Method": {"GET"},
+		}, http.StatusNoContent, map[string]string{
+			"Access-Control-Allow-Origin":  "",
+			"Access-Control-Allow-Methods": "",
+			"Allow":                        "GET, OPTIONS",
+		}},
+		{"preflight from other origin", "OPTIONS", "/api/items", http.Header{
+			"Origin":                        {"https://evil.example.com"},
+			"Access-Control-Request-Method": {"PUT"},
+		}, http.StatusNoContent, map[string]string{
+			"Access-Control-Allow-Origin":  "",
+			"Access-Control-Allow-Methods": "",
+		}},
+		{"preflight without CORS", "OPTIONS", "/private", http.Header{
+			"Origin":                        {"https://app.example.com"},
+			"Access-Control-Request-Method": {"GET"},
+		}, http.StatusNoContent, map[string]string{
+			"Access-Control-Allow-Origin": "",
+			"Allow":                       "GET, OPTIONS",
+		}},
+		{"actual request", "GET", "/api/items", http.Header{"Origin": {"https://app.example.com"}},
+			http.StatusOK, map[string]string{
+				"Access-Control-Allow-Origin":   "https://app.example.com",
+				"Access-Control-Expose-Headers": "X-Total",
+			}},
+		{"rejected request", "DELETE", "/api/items", http.Header{"Origin": {"https://app.example.com"}},
+			http.StatusUnauthorized, map[string]string{
+				"Access-Control-Allow-Origin": "https://app.example.com",
+			}},
+		{"same origin request", "GET", "/api/items", nil, http.StatusOK, map[string]string{
+			"Access-Control-Allow-Origin": "",
+		}},
+		{"wildcard with credentials", "OPTIONS", "/public/feed", http.Header{
+			"Origin":                         {"https://any.example.org"},
+			"Access-Control-Request-Method":  {"GET"},
+			"Access-Control-Request-Headers": {"x-custom"},
+		}, http.StatusNoContent, map[string]string{
+			"Access-Control-Allow-Origin":      "https://any.example.org",
+			"Access-Control-Allow-Credentials": "true",
+			"Access-Control-Allow-Headers":     "x-custom",
+		}},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, test.path, nil)
+		if test.header != nil {
+			r.Header = test.header
+		}
+		w := httptest.NewRecorder()
+		rt.ServeHTTP(w, r)
+		if w.Code != test.code {
+			t.Errorf("%s: got status %d, want %d", test.name, w.Code, test.code)
+		}
+		for key, want := range test.want {
+			if got := w.Header().Get(key); got != want {
+				t.Errorf("%s: got %s %q, want %q", test.name, key, got, want)
+			}
+		}
+	}
+}
+
+func TestCORSAfterRoutes(t *testing.T) {
+	rt := NewRouter()
+	rt.GET("/", func(http.ResponseWriter, *http.Request) {})
+	if recv := catchPanic(func() { rt.CORS(CORS{AllowedOrigins: []string{"*"}}) }); recv == nil {
+		t.Error("no panic for CORS after routes")
+	}
+}
@@ -0,0 +1,91 @@
+package pathmatcher
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// WriteDOT writes the radix tree as a Graphviz DOT digraph. Node shapes
+// distinguish root, static, param and catch-all nodes, edges are labeled with
+// the index byte used to select the child, and nodes holding a value are
+// labeled with the full pattern registered there.
+func (m *Matcher[V]) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph pathmatcher {")
+	fmt.Fprintln(bw, "\tnode [fontname=\"monospace\"];")
+	id := 0
+	writeDOTNode(bw, "\t", "n", m.tree, &id)
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// WriteDOT writes the radix tree of every method as a Graphviz DOT digraph,
+// with one cluster per method. See Matcher.WriteDOT for the styling.
+func (m *HttpMatcher[V]) WriteDOT(w io.Writer) error {
+	methods := make([]string, 0, len(m.trees))
+	for method := range m.trees {
+		methods = append(methods, method)
+	}
+	slices.Sort(methods)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph pathmatcher {")
+	fmt.Fprintln(bw, "\tnode [fontname=\"monospace\"];")
+	for _, method := range methods {
+		fmt.Fprintf(bw, "\tsubgraph \"cluster_%s\" {\n", method)
+		fmt.Fprintf(bw, "\t\tlabel=%s;\n", dotQuote(method))
+		id := 0
+		writeDOTNode(bw, "\t\t", method+"_n", m.trees[method], &id)
+		fmt.Fprintln(bw, "\t}")
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// dotShapes maps node types to their Graphviz node attributes.
+var dotShapes = [...]string{
+	static:   "shape=box",
+	root:     "shape=box, style=bold",
+	param:    "shape=ellipse",
+	catchAll: "shape=ellipse, style=dashed",
+}
+
+// writeDOTNode writes n and its subtree, returning the DOT identifier of n.
+func writeDOTNode[V any](w io.Writer, indent, prefix string, n *node[V], id *int) string {
+	name := fmt.Sprintf("%q", prefix+fmt.Sprint(*id))
+	*id++
+
+	label := n.path
+	if n.value != nil {
+		label += "\n" + n.fullPath
+	}
+	shape := "shape=box"
+	if int(n.nType) < len(dotShapes) {
+		shape = dotShapes[n.nType]
+	}
+	if n.value != nil {
+		shape += ", peripheries=2"
+	}
+	fmt.Fprintf(w, "%s%s [label=%s, %s];\n", indent, name, dotQuote(label), shape)
+
+	for i, child := range n.children {
+		childName := writeDOTNode(w, indent, prefix, child, id)
+		edge := string(child.path[:min(len(child.path), 1)])
+		if !n.wildChild && i < len(n.indices) {
+			edge = n.indices[i : i+1]
+		}
+		fmt.Fprintf(w, "%s%s -> %s [label=%s];\n", indent, name, childName, dotQuote(edge))
+	}
+	return name
+}
+
+// dotQuote quotes s as a DOT string, turning newlines into centered line
+// breaks.
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,82 @@
+package pathmatcher
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatcherWriteDOT(t *testing.T) {
+	m := NewMatcher[int]()
+	routes := [...]string{
+		"/",
+		"/blog/:post/",
+		"/src/*filepath",
+		`/say "hi"`,
+	}
+	for i, route := range routes {
+		m.Add(route, i)
+	}
+
+	want := strings.Join([]string{
+		`digraph pathmatcher {`,
+		`	node [fontname="monospace"];`,
+		`	"n0" [label="/\n/", shape=box, style=bold, peripheries=2];`,
+		`	"n1" [label="s", shape=box];`,
+		`	"n2" [label="rc", shape=box];`,
+		`	"n3" [label="", shape=ellipse, style=dashed];`,
+		`	"n4" [label="/*filepath\n/src/*filepath", shape=ellipse, style=dashed, peripheries=2];`,
+		`	"n3" -> "n4" [label="/"];`,
+		`	"n2" -> "n3" [label="/"];`,
+		`	"n1" -> "n2" [label="r"];`,
+		`	"n5" [label="ay \"hi\"\n/say \"hi\"", shape=box, peripheries=2];`,
+		`	"n1" -> "n5" [label="a"];`,
+		`	"n0" -> "n1" [label="s"];`,
+		`	"n6" [label="blog/", shape=box];`,
+		`	"n7" [label=":post", shape=ellipse];`,
+		`	"n8" [label="/\n/blog/:post/", shape=box, peripheries=2];`,
+		`	"n7" -> "n8" [label="/"];`,
+		`	"n6" -> "n7" [label=":"];`,
+		`	"n0" -> "n6" [label="b"];`,
+		`}`,
+		``,
+	}, "\n")
+
+	var sb strings.Builder
+	if err := m.WriteDOT(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if got := sb.String(); got != want {
+		t.Errorf("wrong DOT output:\n%s\nexpected:\n%s", got, want)
+	}
+}
+
+func TestHttpMatcherWriteDOT(t *testing.T) {
+	m := NewHttpMatcher[int]()
+	m.POST("/b/:c", 2)
+	m.GET("/a", 1)
+
+	want := strings.Join([]string{
+		`digraph pathmatcher {`,
+		`	node [fontname="monospace"];`,
+		`	subgraph "cluster_GET" {`,
+		`		label="GET";`,
+		`		"GET_n0" [label="/a\n/a", shape=box, style=bold, peripheries=2];`,
+		`	}`,
+		`	subgraph "cluster_POST" {`,
+		`		label="POST";`,
+		`		"POST_n0" [label="/b/", shape=box, style=bold];`,
+		`		"POST_n1" [label=":c\n/b/:c", shape=ellipse, peripheries=2];`,
+		`		"POST_n0" -> "POST_n1" [label=":"];`,
+		`	}`,
+		`}`,
+		``,
+	}, "\n")
+
+	var sb strings.Builder
+	if err := m.WriteDOT(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if got := sb.String(); got != want {
+		t.Errorf("wrong DOT output:\n%s\nexpected:\n%s", got, want)
+	}
+}
@@ -0,0 +1,109 @@
+package pathmatcher
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"golang.org/x/exp/slices"
+)
+
+func (t nodeType) String() string {
+	switch t {
+	case static:
+		return "static"
+	case root:
+		return "root"
+	case param:
+		return "param"
+	case catchAll:
+		return "catchAll"
+	default:
+		return fmt.Sprintf("nodeType(%d)", uint8(t))
+	}
+}
+
+// Dump writes a table of the radix tree to w, one node per line, in the format
+// shown in the README. Each child is drawn below the last byte of its parent's
+// path, so following the branches from the root spells out a route.
+func (m *Matcher[V]) Dump(w io.Writer) error {
+	return dumpTree(w, m.tree)
+}
+
+// String returns the output of Dump.
+func (m *Matcher[V]) String() string {
+	var sb strings.Builder
+	m.Dump(&sb)
+	return sb.String()
+}
+
+// Dump writes a table of the radix tree of every method to w, ordered by
+// method name. See Matcher.Dump for the format.
+func (m *HttpMatcher[V]) Dump(w io.Writer) error {
+	methods := make([]string, 0, len(m.trees))
+	for method := range m.trees {
+		methods = append(methods, method)
+	}
+	slices.Sort(methods)
+
+	for i, method := range methods {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, method+"\n"); err != nil {
+			return err
+		}
+		if err := dumpTree(w, m.trees[method]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// String returns the output of Dump.
+func (m *HttpMatcher[V]) String() string {
+	var sb strings.Builder
+	m.Dump(&sb)
+	return sb.String()
+}
+
+func dumpTree[V any](w io.Writer, n *node[V]) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(tw, "Priority\tPath\tType\tWild\tIndices\tHandle")
+	dumpNode(tw, n, "", "")
+	return tw.Flush()
+}
+
+func dumpNode[V any](w io.Writer, n *node[V], prefix, branch string) {
+	handle := "nil"
+	if n.value != nil {
+		handle = n.fullPath
+	}
+	fmt.Fprintf(w, "%d\t%s%s%s\t%s\t%t\t%q\t%s\n",
+		n.priority, prefix, branch, n.path, n.nType, n.wildChild, n.indices, handle)
+
+	// Children are drawn under the last rune of this node's path. The column
+	// of the branch itself continues the parent's line if it has more
+	// siblings below.
+	switch branch {
+	case "├":
+		prefix += "|"
+	case "└":
+		prefix += " "
+	}
+	if width := utf8.RuneCountInString(n.path) - 1; width > 0 {
+		prefix += strings.Repeat(" ", width)
+	}
+
+	for i, child := range n.children {
+		branch := "├"
+		if i == len(n.children)-1 {
+			branch = "└"
+		}
+		dumpNode(w, child, prefix, branch)
+	}
+}
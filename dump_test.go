@@ -0,0 +1,65 @@
+package pathmatcher
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatcherDump(t *testing.T) {
+	m := NewMatcher[int]()
+	routes := [...]string{
+		"/",
+		"/search/",
+		"/support/",
+		"/blog/:post/",
+		"/about-us/",
+		"/about-us/team/",
+		"/contact/",
+		"/src/*filepath",
+	}
+	for i, route := range routes {
+		m.Add(route, i)
+	}
+
+	want := strings.Join([]string{
+		`Priority   Path              Type       Wild    Indices   Handle`,
+		`8          /                 root       false   "sabc"    /`,
+		`3          ├s                static     false   "eur"     nil`,
+		`1          |├earch/          static     false   ""        /search/`,
+		`1          |├upport/         static     false   ""        /support/`,
+		`1          |└rc              static     false   "/"       nil`,
+		`1          |  └              catchAll   true    ""        nil`,
+		`1          |   └/*filepath   catchAll   false   ""        /src/*filepath`,
+		`2          ├about-us/        static     false   "t"       /about-us/`,
+		`1          |        └team/   static     false   ""        /about-us/team/`,
+		`1          ├blog/            static     true    ""        nil`,
+		`1          |    └:post       param      false   ""        nil`,
+		`1          |         └/      static     false   ""        /blog/:post/`,
+		`1          └contact/         static     false   ""        /contact/`,
+		``,
+	}, "\n")
+	if got := m.String(); got != want {
+		t.Errorf("wrong dump:\n%s\nexpected:\n%s", got, want)
+	}
+}
+
+func TestHttpMatcherDump(t *testing.T) {
+	m := NewHttpMatcher[int]()
+	m.POST("/b/:c", 2)
+	m.GET("/a", 1)
+
+	want := strings.Join([]string{
+		`GET`,
+		`Priority   Path   Type   Wild    Indices   Handle`,
+		`1          /a     root   false   ""        /a`,
+		``,
+		`POST`,
+		`Priority   Path    Type    Wild    Indices   Handle`,
+		`1          /b/     root    true    ""        nil`,
+		`1            └:c   param   false   ""        /b/:c`,
+		``,
+	}, "\n")
+	if got := m.String(); got != want {
+		t.Errorf("wrong dump:\n%s\nexpected:\n%s", got, want)
+	}
+}
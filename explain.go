@@ -0,0 +1,237 @@
+package pathmatcher
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StopReason describes why a lookup stopped walking the tree.
+type StopReason uint8
+
+const (
+	// StopMatched means a value was found for the path.
+	StopMatched StopReason = iota
+	// StopNoTree means there are no routes for the requested method.
+	StopNoTree
+	// StopPrefixMismatch means the path diverged from a node's path.
+	StopPrefixMismatch
+	// StopNoIndex means no child starts with the next byte of the path.
+	StopNoIndex
+	// StopWildcardEnd means a param consumed a segment but the path continues
+	// below a param node without children.
+	StopWildcardEnd
+	// StopNoValue means the path ended on a node without a value.
+	StopNoValue
+)
+
+func (r StopReason) String() string {
+	switch r {
+	case StopMatched:
+		return "matched"
+	case StopNoTree:
+		return "no routes for method"
+	case StopPrefixMismatch:
+		return "path diverges from node path"
+	case StopNoIndex:
+		return "no child for next byte"
+	case StopWildcardEnd:
+		return "path continues after wildcard without children"
+	case StopNoValue:
+		return "node has no value"
+	default:
+		return fmt.Sprintf("StopReason(%d)", uint8(r))
+	}
+}
+
+// ExplainStep is a single node visited during a lookup.
+type ExplainStep struct {
+	Node      string // path of the visited node
+	Type      string // static, root, param or catchAll
+	Remaining string // part of the request path left when entering the node
+}
+
+// Explanation records how a lookup walked the tree, as returned by Explain.
+type Explanation struct {
+	Path   string
+	Steps  []ExplainStep
+	Params Params
+	Match  string // the matched pattern, if any
+	Stop   StopReason
+
+	// TSR is set if a trailing slash redirect is recommended. TSRRule
+	// describes the rule that made the recommendation.
+	TSR     bool
+	TSRRule string
+}
+
+// String formats the explanation for humans, one visited node per line.
+func (e *Explanation) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "lookup %q\n", e.Path)
+	for i, step := range e.Steps {
+		fmt.Fprintf(&sb, "  %d. %s node %q, remaining %q\n", i+1, step.Type, step.Node, step.Remaining)
+	}
+	for _, p := range e.Params {
+		fmt.Fprintf(&sb, "  param %s = %q\n", p.Key, p.Value)
+	}
+	fmt.Fprintf(&sb, "  stop: %s", e.Stop)
+	if e.Match != "" {
+		fmt.Fprintf(&sb, " %q", e.Match)
+	}
+	sb.WriteByte('\n')
+	if e.TSR {
+		fmt.Fprintf(&sb, "  trailing slash redirect: %s\n", e.TSRRule)
+	}
+	return sb.String()
+}
+
+// Explain walks the tree like Find and reports every node visited, the params
+// captured, where and why the walk stopped, and which trailing slash rule
+// fired, if any.
+func (m *Matcher[V]) Explain(path string) *Explanation {
+	return m.tree.explain(path)
+}
+
+// Explain walks the tree for method like Find and reports every node visited,
+// the params captured, where and why the walk stopped, and which trailing
+// slash rule fired, if any.
+func (m *HttpMatcher[V]) Explain(method, path string) *Explanation {
+	tree, ok := m.trees[method]
+	if !ok {
+		return &Explanation{Path: path, Stop: StopNoTree}
+	}
+	return tree.explain(path)
+}
+
+// explain mirrors findMatch step by step, recording the walk instead of
+// optimizing it. Keep both in sync.
+func (n *node[V]) explain(path string) (e *Explanation) {
+	e = &Explanation{Path: path}
+	step := func(n *node[V], path string) {
+		e.Steps = append(e.Steps, ExplainStep{Node: n.path, Type: n.nType.String(), Remaining: path})
+	}
+	tsr := func(ok bool, rule string) {
+		if ok {
+			e.TSR = true
+			e.TSRRule = rule
+		}
+	}
+
+walk:
+	for {
+		step(n, path)
+		prefix := n.path
+		if len(path) > len(prefix) {
+			if path[:len(prefix)] == prefix {
+				path = path[len(prefix):]
+
+				if !n.wildChild {
+					idxc := path[0]
+					for i, c := range []byte(n.indices) {
+						if c == idxc {
+							n = n.children[i]
+							continue walk
+						}
+					}
+
+					e.Stop = StopNoIndex
+					tsr(path == "/" && n.value != nil,
+						"remove trailing slash, node "+quote(n.path)+" has a value")
+					return
+				}
+
+				n = n.children[0]
+				step(n, path)
+				switch n.nType {
+				case param:
+					end := 0
+					for end < len(path) && path[end] != '/' {
+						end++
+					}
+					e.Params = append(e.Params, Param{Key: n.path[1:], Value: path[:end]})
+
+					if end < len(path) {
+						if len(n.children) > 0 {
+							path = path[end:]
+							n = n.children[0]
+							continue walk
+						}
+
+						e.Stop = StopWildcardEnd
+						tsr(len(path) == end+1,
+							"remove trailing slash after param "+quote(n.path))
+						return
+					}
+
+					if n.value != nil {
+						e.Stop = StopMatched
+						e.Match = n.fullPath
+						return
+					}
+					e.Stop = StopNoValue
+					if len(n.children) == 1 {
+						n = n.children[0]
+						tsr((n.path == "/" && n.value != nil) || (n.path == "" && n.indices == "/"),
+							"add trailing slash after param, child "+quote(n.path)+" has a value")
+					}
+					return
+
+				case catchAll:
+					e.Params = append(e.Params, Param{Key: n.path[2:], Value: path})
+					if n.value != nil {
+						e.Stop = StopMatched
+						e.Match = n.fullPath
+					} else {
+						e.Stop = StopNoValue
+					}
+					return
+
+				default:
+					panic("invalid node type")
+				}
+			}
+		} else if path == prefix {
+			if n.value != nil {
+				e.Stop = StopMatched
+				e.Match = n.fullPath
+				return
+			}
+
+			e.Stop = StopNoValue
+			if path == "/" && n.wildChild && n.nType != root {
+				tsr(true, "remove trailing slash, node "+quote(n.path)+" only has a wildcard child")
+				return
+			}
+
+			if path == "/" && n.nType == static {
+				tsr(true, "remove trailing slash, static node "+quote(n.path)+" has no value")
+				return
+			}
+
+			for i, c := range []byte(n.indices) {
+				if c == '/' {
+					n = n.children[i]
+					tsr((len(n.path) == 1 && n.value != nil) ||
+						(n.nType == catchAll && n.children[0].value != nil),
+						"add trailing slash, child "+quote(n.path)+" has a value")
+					return
+				}
+			}
+			return
+		}
+
+		e.Stop = StopPrefixMismatch
+		if path == "/" {
+			tsr(true, "remove trailing slash, path is only '/'")
+		} else {
+			tsr(len(prefix) == len(path)+1 && prefix[len(path)] == '/' &&
+				path == prefix[:len(prefix)-1] && n.value != nil,
+				"add trailing slash, node "+quote(n.path)+" has a value")
+		}
+		return
+	}
+}
+
+func quote(s string) string {
+	return "'" + s + "'"
+}
@@ -0,0 +1,84 @@
+package pathmatcher
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExplainAgreesWithFind(t *testing.T) {
+	m := NewMatcher[int]()
+	routes := [...]string{
+		"/hi",
+		"/b/",
+		"/search/:query",
+		"/cmd/:tool/",
+		"/src/*filepath",
+		"/x",
+		"/x/y",
+		"/y/",
+		"/y/z",
+		"/0/:id",
+		"/0/:id/1",
+		"/1/:id/",
+		"/1/:id/2",
+		"/aa",
+		"/a/",
+		"/admin",
+		"/admin/:category",
+		"/admin/:category/:page",
+		"/doc",
+		"/doc/go_faq.html",
+		"/doc/go1.html",
+		"/no/a",
+		"/no/b",
+		"/api/hello/:name",
+		"/vendor/:x/*y",
+	}
+	for i, route := range routes {
+		m.Add(route, i)
+	}
+
+	paths := append(routes[:],
+		"/", "/hi/", "/b", "/search/gopher/", "/cmd/vet", "/src", "/x/", "/y",
+		"/0/go/", "/1/go", "/a", "/admin/", "/admin/config/",
+		"/admin/config/permissions/", "/doc/", "/vendor/x", "/no", "/no/", "/_",
+		"/_/", "/api/world/abc", "/search/a/b",
+	)
+	for _, path := range paths {
+		match, _, params, redir := m.Find(path)
+		e := m.Explain(path)
+		if e.Match != match || e.TSR != redir || (e.Stop == StopMatched) != (match != "") {
+			t.Errorf("explain disagrees with find for '%s': got %+v, want match '%s', redir %t", path, e, match, redir)
+		}
+		if match != "" && !reflect.DeepEqual(e.Params, params) {
+			t.Errorf("explain params for '%s': got %+v, want %+v", path, e.Params, params)
+		}
+		if len(e.Steps) == 0 {
+			t.Errorf("no steps recorded for '%s'", path)
+		}
+		if e.TSR && e.TSRRule == "" {
+			t.Errorf("no TSR rule recorded for '%s'", path)
+		}
+	}
+}
+
+func TestExplainString(t *testing.T) {
+	m := NewHttpMatcher[int]()
+	m.GET("/users/:id/", 1)
+
+	e := m.Explain("GET", "/users/42")
+	want := `lookup "/users/42"
+  1. root node "/users/", remaining "/users/42"
+  2. param node ":id", remaining "42"
+  param id = "42"
+  stop: node has no value
+  trailing slash redirect: add trailing slash after param, child '/' has a value
+`
+	if got := e.String(); got != want {
+		t.Errorf("wrong explanation:\n%s\nexpected:\n%s", got, want)
+	}
+
+	if e := m.Explain("POST", "/users/42"); e.Stop != StopNoTree {
+		t.Errorf("expected stop reason '%s', got '%s'", StopNoTree, e.Stop)
+	}
+}
@@ -0,0 +1,207 @@
+package pathmatcher
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+)
+
+// RouteSpec is the serializable description of a single route. Route tables
+// are written and read as a JSON array of RouteSpecs, or as JSON Lines with one
+// RouteSpec per line.
+//
+// Name is the key used to look up the route's value when loading a table.
+// Query and Header list the requirements of routes added with
+// HttpMatcher.AddQualified.
+type RouteSpec struct {
+	Method string      `json:"method,omitempty"`
+	Path   string      `json:"path"`
+	Name   string      `json:"name"`
+	Meta   Meta        `json:"meta,omitempty"`
+	Query  url.Values  `json:"query,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+}
+
+// Export describes every route in the matcher and its metadata, sorted by path.
+// The name function maps each value to the key stored in RouteSpec.Name.
+func (m *Matcher[V]) Export(name func(V) string) []RouteSpec {
+	var specs []RouteSpec
+	m.Walk(func(route Route[V]) {
+		specs = append(specs, RouteSpec{Path: route.Path, Name: name(route.Value), Meta: route.Meta})
+	})
+	sortSpecs(specs)
+	return specs
+}
+
+// Export describes every route in the matcher and its metadata, sorted by
+// method and path. The name function maps each value to the key stored in
+// RouteSpec.Name.
+func (m *HttpMatcher[V]) Export(name func(V) string) []RouteSpec {
+	var specs []RouteSpec
+	m.Walk(func(route Route[V]) {
+		specs = append(specs, RouteSpec{
+			Method: route.Method,
+			Path:   route.Path,
+			Name:   name(route.Value),
+			Meta:   route.Meta,
+			Query:  route.Query,
+			Header: route.Header,
+		})
+	})
+	sortSpecs(specs)
+	return specs
+}
+
+func sortSpecs(specs []RouteSpec) {
+	sort.SliceStable(specs, func(i, j int) bool {
+		if specs[i].Method != specs[j].Method {
+			return specs[i].Method < specs[j].Method
+		}
+		return specs[i].Path < specs[j].Path
+	})
+}
+
+// WriteJSON writes specs as an indented JSON array.
+func WriteJSON(w io.Writer, specs []RouteSpec) error {
+	if specs == nil {
+		specs = []RouteSpec{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(specs)
+}
+
+// WriteJSONL writes specs as JSON Lines, one RouteSpec per line.
+func WriteJSONL(w io.Writer, specs []RouteSpec) error {
+	enc := json.NewEncoder(w)
+	for _, spec := range specs {
+		if err := enc.Encode(spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadRouteSpecs reads a route table written by WriteJSON or WriteJSONL. The
+// format is detected from the first non-space byte: '[' starts a JSON array,
+// anything else is read as JSON Lines. Blank lines in JSON Lines input are
+// skipped.
+func ReadRouteSpecs(r io.Reader) ([]RouteSpec, error) {
+	br := bufio.NewReader(r)
+	for {
+		c, err := br.ReadByte()
+		if err == io.EOF {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+			continue
+		}
+		br.UnreadByte()
+		if c == '[' {
+			var specs []RouteSpec
+			if err := json.NewDecoder(br).Decode(&specs); err != nil {
+				return nil, fmt.Errorf("pathmatcher: reading routes: %w", err)
+			}
+			return specs, nil
+		}
+		break
+	}
+
+	var specs []RouteSpec
+	scanner := bufio.NewScanner(br)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var spec RouteSpec
+		if err := json.Unmarshal(text, &spec); err != nil {
+			return nil, fmt.Errorf("pathmatcher: reading routes: line %d: %w", line, err)
+		}
+		specs = append(specs, spec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("pathmatcher: reading routes: %w", err)
+	}
+	return specs, nil
+}
+
+// LoadMatcher builds a Matcher from a route table read with ReadRouteSpecs.
+// The value function maps each spec (usually by its Name) to the value to
+// register along with the spec's Meta. Routes with a method, invalid routes and
+// conflicting routes are reported as errors instead of panicking.
+func LoadMatcher[V any](r io.Reader, value func(spec RouteSpec) (V, error)) (*Matcher[V], error) {
+	specs, err := ReadRouteSpecs(r)
+	if err != nil {
+		return nil, err
+	}
+	m := NewMatcher[V]()
+	for i, spec := range specs {
+		if spec.Method != "" {
+			return nil, fmt.Errorf("pathmatcher: route %d: unexpected method '%s' for path '%s'", i+1, spec.Method, spec.Path)
+		}
+		v, err := value(spec)
+		if err != nil {
+			return nil, fmt.Errorf("pathmatcher: route %d: %w", i+1, err)
+		}
+		if err := catchAdd(func() { m.AddMeta(spec.Path, v, spec.Meta) }); err != nil {
+			return nil, fmt.Errorf("pathmatcher: route %d: %w", i+1, err)
+		}
+	}
+	return m, nil
+}
+
+// LoadHttpMatcher builds an HttpMatcher from a route table read with
+// ReadRouteSpecs. The value function maps each spec (usually by its Name) to
+// the value to register along with the spec's Meta. Invalid and conflicting
+// routes are reported as errors instead of panicking.
+//
+// Metadata belongs to an endpoint, see HttpMatcher.Meta, so the specs of
+// qualified values share it with the other specs of the same method and path.
+// Specs of the same endpoint with different Meta are reported as conflicting.
+func LoadHttpMatcher[V any](r io.Reader, value func(spec RouteSpec) (V, error)) (*HttpMatcher[V], error) {
+	specs, err := ReadRouteSpecs(r)
+	if err != nil {
+		return nil, err
+	}
+	m := NewHttpMatcher[V]()
+	for i, spec := range specs {
+		v, err := value(spec)
+		if err != nil {
+			return nil, fmt.Errorf("pathmatcher: route %d: %w", i+1, err)
+		}
+		if meta := m.Meta(spec.Method, spec.Path); meta != nil && spec.Meta != nil && !reflect.DeepEqual(meta, spec.Meta) {
+			return nil, fmt.Errorf("pathmatcher: route %d: meta differs from the meta of endpoint '%s %s'", i+1, spec.Method, spec.Path)
+		}
+		add := func() { m.AddMeta(spec.Method, spec.Path, v, spec.Meta) }
+		if len(spec.Query) > 0 || len(spec.Header) > 0 {
+			add = func() {
+				m.AddQualified(spec.Method, spec.Path, spec.Query, spec.Header, v)
+				m.setMeta(spec.Method, spec.Path, spec.Meta)
+			}
+		}
+		if err := catchAdd(add); err != nil {
+			return nil, fmt.Errorf("pathmatcher: route %d: %w", i+1, err)
+		}
+	}
+	return m, nil
+}
+
+// catchAdd converts a panic raised while registering a route into an error.
+func catchAdd(add func()) (err error) {
+	defer func() {
+		if recv := recover(); recv != nil {
+			err = fmt.Errorf("%v", recv)
+		}
+	}()
+	add()
+	return nil
+}
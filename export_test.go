@@ -0,0 +1,143 @@
+package pathmatcher
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExportRoundTrip(t *testing.T) {
+	m := NewHttpMatcher[string]()
+	m.GET("/users/:id", "getUser")
+	m.POST("/users", "createUser")
+	m.GET("/src/*filepath", "files")
+	m.GET("/", "index")
+
+	specs := m.Export(func(v string) string { return v })
+	want := []RouteSpec{
+		{Method: "GET", Path: "/", Name: "index"},
+		{Method: "GET", Path: "/src/*filepath", Name: "files"},
+		{Method: "GET", Path: "/users/:id", Name: "getUser"},
+		{Method: "POST", Path: "/users", Name: "createUser"},
+	}
+	if !reflect.DeepEqual(specs, want) {
+		t.Fatalf("wrong export: expected %+v, got %+v", want, specs)
+	}
+
+	for _, write := range []func(*bytes.Buffer, []RouteSpec) error{
+		func(b *bytes.Buffer, s []RouteSpec) error { return WriteJSON(b, s) },
+		func(b *bytes.Buffer, s []RouteSpec) error { return WriteJSONL(b, s) },
+	} {
+		var buf bytes.Buffer
+		if err := write(&buf, specs); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := LoadHttpMatcher(&buf, func(spec RouteSpec) (string, error) {
+			return spec.Name, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := loaded.Export(func(v string) string { return v }); !reflect.DeepEqual(got, want) {
+			t.Errorf("wrong routes after load: expected %+v, got %+v", want, got)
+		}
+		match, value, params, _ := loaded.Find("GET", "/users/42")
+		if match != "/users/:id" || value != "getUser" || params.ByName("id") != "42" {
+			t.Errorf("wrong match after load: %s, %s, %+v", match, value, params)
+		}
+	}
+}
+
+func TestLoadMatcher(t *testing.T) {
+	input := `
+{"path": "/hello", "name": "world"}
+
+{"path": "/foo/:bar", "name": "baz", "meta": {"owner": "ops"}}
+`
+	var metas []Meta
+	m, err := LoadMatcher(strings.NewReader(input), func(spec RouteSpec) (string, error) {
+		metas = append(metas, spec.Meta)
+		return spec.Name, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metas[1]["owner"] != "ops" {
+		t.Errorf("meta not passed to value function: %+v", metas)
+	}
+	match, value, _, _ := m.Find("/foo/x")
+	if value != "baz" {
+		t.Errorf("wrong value: expected 'baz', got '%s'", value)
+	}
+	if meta := m.Meta(match); meta["owner"] != "ops" {
+		t.Errorf("meta not registered: %+v", meta)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	errUnknown := errors.New("unknown route")
+
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`{"path": "/a"`, "line 1"},
+		{"{\"path\": \"/a\"}\n{\"path\": ", "line 2"},
+		{`[{"path": "/a"}, {"path": "/a"}]`, "route 2: a handle is already registered"},
+		{`[{"path": "/a/:b"}, {"path": "/a/c"}]`, "route 2: 'c' in new path"},
+		{`[{"path": "/a", "method": "GET"}]`, "route 1: unexpected method"},
+		{`[{"path": "/a", "name": "unknown"}]`, "route 1: unknown route"},
+	}
+	for _, test := range tests {
+		_, err := LoadMatcher(strings.NewReader(test.input), func(spec RouteSpec) (int, error) {
+			if spec.Name == "unknown" {
+				return 0, errUnknown
+			}
+			return 0, nil
+		})
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("wrong error for %q: expected '%s', got '%v'", test.input, test.err, err)
+		}
+	}
+}
+
+func TestLoadHttpMatcherQualifiedMeta(t *testing.T) {
+	input := `[
+	{"method": "GET", "path": "/users/:id", "name": "json", "meta": {"owner": "users"}},
+	{"method": "GET", "path": "/users/:id", "name": "html", "meta": {"owner": "users"}, "header": {"Accept": ["text/html"]}}
+]`
+	m, err := LoadHttpMatcher(strings.NewReader(input), func(spec RouteSpec) (string, error) {
+		return spec.Name, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := Meta{"owner": "users"}
+	want := []RouteSpec{
+		{Method: "GET", Path: "/users/:id", Name: "json", Meta: meta},
+		{Method: "GET", Path: "/users/:id", Name: "html", Meta: meta, Header: http.Header{"Accept": {"text/html"}}},
+	}
+	if got := m.Export(func(v string) string { return v }); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong export: expected %+v, got %+v", want, got)
+	}
+	r := httptest.NewRequest("GET", "/users/1", nil)
+	r.Header.Set("Accept", "text/html")
+	if res := m.FindRequest(r); res.Value != "html" || !reflect.DeepEqual(res.Meta, meta) {
+		t.Errorf("wrong result %+v", res)
+	}
+
+	input = `[
+	{"method": "GET", "path": "/users/:id", "name": "json", "meta": {"owner": "users"}},
+	{"method": "GET", "path": "/users/:id", "name": "html", "meta": {"owner": "web"}, "header": {"Accept": ["text/html"]}}
+]`
+	_, err = LoadHttpMatcher(strings.NewReader(input), func(spec RouteSpec) (string, error) {
+		return spec.Name, nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "route 2: meta differs") {
+		t.Errorf("wrong error for conflicting meta: %v", err)
+	}
+}
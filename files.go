@@ -0,0 +1,179 @@
+package pathmatcher
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ServeFiles serves files from root for GET and HEAD requests, like
+// httprouter's ServeFiles. The path must end with "/*filepath", which names
+// the file in root. For example if root is http.Dir("/var/www") and path is
+// "/src/*filepath", "/src/app.js" serves the file "/var/www/app.js".
+//
+// A request for a directory serves its index.html, there is no directory
+// listing. If the client accepts it, a precompressed variant with the suffix
+// ".br" or ".gz" is served in place of the file with the matching
+// Content-Encoding. Responses carry Last-Modified, unless the modification
+// time is zero as for an embed.FS, and an ETag holding a hash of the served
+// file, which is computed once per file, modification time and size.
+// Conditional and range requests are handled by http.ServeContent.
+func (g *RouteGroup) ServeFiles(path string, root http.FileSystem) {
+	if !strings.HasSuffix(path, "/*filepath") {
+		panic("path must end with /*filepath in path '" + path + "'")
+	}
+	g.Handle(http.MethodGet, path, &fileServer{root: root})
+}
+
+// ServeFS is like ServeFiles for an fs.FS, such as an embed.FS.
+func (g *RouteGroup) ServeFS(path string, fsys fs.FS) {
+	g.ServeFiles(path, http.FS(fsys))
+}
+
+// precompressed lists the variants served in place of a file, in order of
+// preference.
+var precompressed = []struct{ encoding, suffix string }{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+type fileServer struct {
+	root  http.FileSystem
+	etags sync.Map // etagKey -> string
+}
+
+// etagKey identifies a version of a file. The content of files without a
+// modification time, like those of an embed.FS, doesn't change.
+type etagKey struct {
+	name    string
+	modTime int64
+	size    int64
+}
+
+// etag returns the ETag of the file f named name, hashing its content on the
+// first request for this version of the file.
+func (s *fileServer) etag(name string, f http.File, stat fs.FileInfo) (string, error) {
+	key := etagKey{name, stat.ModTime().UnixNano(), stat.Size()}
+	if etag, ok := s.etags.Load(key); ok {
+		return etag.(string), nil
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	etag := fmt.Sprintf(`"%x"`, h.Sum(nil)[:16])
+	s.etags.Store(key, etag)
+	return etag, nil
+}
+
+func (s *fileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := path.Clean("/" + ParamsFromContext(r.Context()).ByName("filepath"))
+
+	f, err := s.root.Open(name)
+	if err != nil {
+		serveFileError(w, r, err)
+		return
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		serveFileError(w, r, err)
+		return
+	}
+
+	if stat.IsDir() {
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			http.Redirect(w, r, path.Base(r.URL.Path)+"/", http.StatusMovedPermanently)
+			return
+		}
+		name = path.Join(name, "index.html")
+		index, err := s.root.Open(name)
+		if err != nil {
+			serveFileError(w, r, err)
+			return
+		}
+		defer index.Close()
+		if stat, err = index.Stat(); err != nil || stat.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+		f = index
+	}
+
+	header := w.Header()
+	if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+		header.Set("Content-Type", ctype)
+	}
+	header.Add("Vary", "Accept-Encoding")
+	served := name
+	for _, variant := range precompressed {
+		if !acceptsEncoding(r, variant.encoding) {
+			continue
+		}
+		cf, err := s.root.Open(name + variant.suffix)
+		if err != nil {
+			continue
+		}
+		defer cf.Close()
+		cstat, err := cf.Stat()
+		if err != nil || cstat.IsDir() {
+			continue
+		}
+		if header.Get("Content-Type") == "" {
+			header.Set("Content-Type", "application/octet-stream")
+		}
+		header.Set("Content-Encoding", variant.encoding)
+		f, stat, served = cf, cstat, name+variant.suffix
+		break
+	}
+
+	etag, err := s.etag(served, f, stat)
+	if err != nil {
+		serveFileError(w, r, err)
+		return
+	}
+	header.Set("ETag", etag)
+	http.ServeContent(w, r, name, stat.ModTime(), f)
+}
+
+// acceptsEncoding reports whether the Accept-Encoding header of r accepts the
+// content coding with a quality above zero.
+func acceptsEncoding(r *http.Request, encoding string) bool {
+	for _, line := range r.Header.Values("Accept-Encoding") {
+		for _, part := range strings.Split(line, ",") {
+			coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			if !strings.EqualFold(strings.TrimSpace(coding), encoding) {
+				continue
+			}
+			q, ok := strings.CutPrefix(strings.TrimSpace(params), "q=")
+			if !ok {
+				return true
+			}
+			quality, err := strconv.ParseFloat(q, 64)
+			return err == nil && quality > 0
+		}
+	}
+	return false
+}
+
+func serveFileError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		http.NotFound(w, r)
+	case errors.Is(err, fs.ErrPermission):
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,134 @@
+package pathmatcher
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestServeFiles(t *testing.T) {
+	modTime := time.Date(2023, 9, 1, 12, 0, 0, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"index.html":      {Data: []byte("index"), ModTime: modTime},
+		"app.js":          {Data: []byte("plain js"), ModTime: modTime},
+		"app.js.gz":       {Data: []byte("gzip js"), ModTime: modTime},
+		"app.js.br":       {Data: []byte("brotli js"), ModTime: modTime},
+		"docs/index.html": {Data: []byte("docs"), ModTime: modTime},
+		"empty/.keep":     {Data: nil, ModTime: modTime},
+	}
+	rt := NewRouter()
+	rt.ServeFS("/static/*filepath", fsys)
+
+	tests := []struct {
+		method, path string
+		header       http.Header
+		code         int
+		body         string
+		ctype        string
+		encoding     string
+		location     string
+		lastModified bool
+	}{
+		{"GET", "/static/app.js", nil, http.StatusOK, "plain js", "text/javascript; charset=utf-8", "", "", true},
+		{"GET", "/static/app.js", http.Header{"Accept-Encoding": {"gzip, deflate"}}, http.StatusOK, "gzip js", "text/javascript; charset=utf-8", "gzip", "", true},
+		{"GET", "/static/app.js", http.Header{"Accept-Encoding": {"gzip, br"}}, http.StatusOK, "brotli js", "text/javascript; charset=utf-8", "br", "", true},
+		{"GET", "/static/app.js", http.Header{"Accept-Encoding": {"br;q=0, gzip;q=0.5"}}, http.StatusOK, "gzip js", "text/javascript; charset=utf-8", "gzip", "", true},
+		{"HEAD", "/static/app.js", nil, http.StatusOK, "", "text/javascript; charset=utf-8", "", "", true},
+		{"GET", "/static/", nil, http.StatusOK, "index", "text/html; charset=utf-8", "", "", true},
+		{"GET", "/static/docs/", nil, http.StatusOK, "docs", "text/html; charset=utf-8", "", "", true},
+		{"GET", "/static/docs", nil, http.StatusMovedPermanently, "<a href=\"/static/docs/\">Moved Permanently</a>.\n\n", "text/html; charset=utf-8", "", "/static/docs/", false},
+		{"GET", "/static/empty/", nil, http.StatusNotFound, "404 page not found\n", "text/plain; charset=utf-8", "", "", false},
+		{"GET", "/static/missing.css", nil, http.StatusNotFound, "404 page not found\n", "text/plain; charset=utf-8", "", "", false},
+		{"GET", "/static/../index.html", nil, http.StatusOK, "index", "text/html; charset=utf-8", "", "", true},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, test.path, nil)
+		if test.header != nil {
+			r.Header = test.header
+		}
+		w := httptest.NewRecorder()
+		rt.ServeHTTP(w, r)
+		if w.Code != test.code || w.Body.String() != test.body ||
+			w.Header().Get("Content-Type") != test.ctype ||
+			w.Header().Get("Content-Encoding") != test.encoding ||
+			w.Header().Get("Location") != test.location ||
+			(w.Header().Get("Last-Modified") != "") != test.lastModified {
+			t.Errorf("%s %s %v: got %d %q, headers %v", test.method, test.path, test.header, w.Code, w.Body.String(), w.Header())
+		}
+	}
+}
+
+func TestServeFilesConditional(t *testing.T) {
+	modTime := time.Date(2023, 9, 1, 12, 0, 0, 0, time.UTC)
+	rt := NewRouter()
+	rt.ServeFS("/*filepath", fstest.MapFS{
+		"a.txt":    {Data: []byte("plain"), ModTime: modTime},
+		"a.txt.gz": {Data: []byte("compressed"), ModTime: modTime},
+	})
+
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest("GET", "/a.txt", nil))
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("missing ETag")
+	}
+
+	r := httptest.NewRequest("GET", "/a.txt", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	rt.ServeHTTP(w, r)
+	if gzipETag := w.Header().Get("ETag"); gzipETag == etag {
+		t.Errorf("compressed variant has the same ETag %s", etag)
+	}
+
+	r = httptest.NewRequest("GET", "/a.txt", nil)
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	rt.ServeHTTP(w, r)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match: got %d, want %d", w.Code, http.StatusNotModified)
+	}
+
+	r = httptest.NewRequest("GET", "/a.txt", nil)
+	r.Header.Set("If-Modified-Since", modTime.Format(http.TimeFormat))
+	w = httptest.NewRecorder()
+	rt.ServeHTTP(w, r)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("If-Modified-Since: got %d, want %d", w.Code, http.StatusNotModified)
+	}
+
+	// Files of an embed.FS have no modification time
+	rt = NewRouter()
+	rt.ServeFS("/*filepath", fstest.MapFS{
+		"b.txt": {Data: []byte("one")},
+		"c.txt": {Data: []byte("two")},
+	})
+	etags := make(map[string]string)
+	for _, path := range []string{"/b.txt", "/c.txt", "/b.txt"} {
+		w = httptest.NewRecorder()
+		rt.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		etag := w.Header().Get("ETag")
+		if prev, ok := etags[path]; etag == "" || ok && etag != prev {
+			t.Errorf("%s: ETag %q, previously %q", path, etag, prev)
+		}
+		etags[path] = etag
+		if lastModified := w.Header().Get("Last-Modified"); lastModified != "" {
+			t.Errorf("%s: unexpected Last-Modified %s", path, lastModified)
+		}
+	}
+	if etags["/b.txt"] == etags["/c.txt"] {
+		t.Errorf("files of the same size have the same ETag %s", etags["/b.txt"])
+	}
+}
+
+func TestServeFilesPattern(t *testing.T) {
+	rt := NewRouter()
+	if recv := catchPanic(func() { rt.ServeFS("/static/:file", fstest.MapFS{}) }); recv == nil {
+		t.Error("no panic for path without /*filepath")
+	}
+	if recv := catchPanic(func() { rt.Group("/assets").ServeFS("/*filepath", fstest.MapFS{}) }); recv != nil {
+		t.Errorf("unexpected panic: %v", recv)
+	}
+}
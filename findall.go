@@ -0,0 +1,101 @@
+package pathmatcher
+
+import (
+	"net/http"
+	"net/url"
+
+	"golang.org/x/exp/slices"
+)
+
+// Candidate is a pattern that could match a path, as listed by FindAll.
+type Candidate[V any] struct {
+	Method  string // the method of the pattern, empty for a Matcher
+	Pattern string
+	Value   V
+	Params  Params
+
+	// Query and Header are the requirements of a qualified value, see
+	// HttpMatcher.AddQualified.
+	Query  url.Values
+	Header http.Header
+}
+
+// FindAll returns the pattern matching path as a list of candidates, for
+// symmetry with PriorityMatcher.FindAll and HttpMatcher.FindAll. The path is
+// matched as is, without normalization. Since the patterns of a Matcher never
+// overlap, there is at most one.
+func (m *Matcher[V]) FindAll(path string) []Candidate[V] {
+	value, ps, match, _ := m.tree.findMatch(path, m.newParams)
+	if value == nil {
+		return nil
+	}
+	c := Candidate[V]{Pattern: match, Value: *value}
+	if ps != nil && len(*ps) > 0 {
+		c.Params = *ps
+	}
+	return []Candidate[V]{c}
+}
+
+func (m *Matcher[V]) newParams() *Params {
+	ps := make(Params, 0, m.maxParams)
+	return &ps
+}
+
+// FindAll returns every value that could be selected for path, across all
+// methods, with the params it matches with. Authorization audits can use it
+// to see every handler a path reaches. Candidates are ordered by method. For
+// an endpoint with qualified values, the value added with Add comes first, if
+// there is one, then every qualified value in the order FindQualified prefers
+// them, with its requirements. Only path params are included, not those
+// extracted from the query; each candidate has its own copy.
+//
+// The path is matched as is, without normalization. Matchers for hosts, see
+// Host, are not included; call FindAll on them separately.
+func (m *HttpMatcher[V]) FindAll(path string) []Candidate[V] {
+	methods := make([]string, 0, len(m.trees))
+	for method := range m.trees {
+		methods = append(methods, method)
+	}
+	slices.Sort(methods)
+
+	var candidates []Candidate[V]
+	for _, method := range methods {
+		value, ps, match, _ := m.trees[method].findMatch(path, m.newParams)
+		if value == nil {
+			continue
+		}
+		var params Params
+		if ps != nil && len(*ps) > 0 {
+			params = *ps
+		}
+
+		q := m.qualified[method][match]
+		if q == nil || q.fallback {
+			candidates = append(candidates, Candidate[V]{
+				Method:  method,
+				Pattern: match,
+				Value:   *value,
+				Params:  slices.Clone(params),
+			})
+		}
+		if q == nil {
+			continue
+		}
+		for _, route := range q.routes {
+			candidates = append(candidates, Candidate[V]{
+				Method:  method,
+				Pattern: match,
+				Value:   route.value,
+				Params:  slices.Clone(params),
+				Query:   route.query,
+				Header:  route.header,
+			})
+		}
+	}
+	return candidates
+}
+
+func (m *HttpMatcher[V]) newParams() *Params {
+	ps := make(Params, 0, m.maxParams)
+	return &ps
+}
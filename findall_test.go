@@ -0,0 +1,63 @@
+package pathmatcher
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestMatcherFindAll(t *testing.T) {
+	m := NewMatcher[string]()
+	for _, route := range freezeRoutes {
+		m.Add(route, route)
+	}
+	for _, path := range freezePaths {
+		match, value, params, _ := m.Find(path)
+		var want []Candidate[string]
+		if match != "" {
+			if len(params) == 0 {
+				params = nil
+			}
+			want = []Candidate[string]{{Pattern: match, Value: value, Params: params}}
+		}
+		if got := m.FindAll(path); !reflect.DeepEqual(got, want) {
+			t.Errorf("FindAll(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestHttpMatcherFindAll(t *testing.T) {
+	m := NewHttpMatcher[string]()
+	m.GET("/users/:id", "get")
+	m.DELETE("/users/:user", "delete")
+	m.PUT("/users/admin", "put admin")
+	m.POST("/users/*path", "post")
+	m.AddHeader("GET", "/users/:id", http.Header{"Accept": {"text/html"}}, "get html")
+	m.AddQuery("PATCH", "/users/:id", url.Values{"op": {"rename"}}, "rename")
+	m.AddQuery("PATCH", "/users/:id", url.Values{"op": nil}, "patch")
+
+	want := []Candidate[string]{
+		{Method: "DELETE", Pattern: "/users/:user", Value: "delete", Params: Params{{"user", "admin"}}},
+		{Method: "GET", Pattern: "/users/:id", Value: "get", Params: Params{{"id", "admin"}}},
+		{Method: "GET", Pattern: "/users/:id", Value: "get html", Params: Params{{"id", "admin"}},
+			Header: http.Header{"Accept": {"text/html"}}},
+		{Method: "PATCH", Pattern: "/users/:id", Value: "rename", Params: Params{{"id", "admin"}},
+			Query: url.Values{"op": {"rename"}}},
+		{Method: "PATCH", Pattern: "/users/:id", Value: "patch", Params: Params{{"id", "admin"}},
+			Query: url.Values{"op": nil}},
+		{Method: "POST", Pattern: "/users/*path", Value: "post", Params: Params{{"path", "/admin"}}},
+		{Method: "PUT", Pattern: "/users/admin", Value: "put admin"},
+	}
+	if got := m.FindAll("/users/admin"); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindAll = %v\nwant %v", got, want)
+	}
+	got := m.FindAll("/users/admin")
+	got[1].Params[0].Value = "changed"
+	if got[2].Params[0].Value != "admin" {
+		t.Errorf("candidates share params: %v", got[2].Params)
+	}
+	if got := m.FindAll("/users"); got != nil {
+		t.Errorf("FindAll = %v, want none", got)
+	}
+}
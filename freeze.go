@@ -0,0 +1,303 @@
+package pathmatcher
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// FrozenMatcher is an immutable snapshot of a Matcher, see Matcher.Freeze.
+type FrozenMatcher[V any] struct {
+	tree       *frozenTree[V]
+	normalizer *Normalizer
+}
+
+// FrozenHttpMatcher is an immutable snapshot of an HttpMatcher, see
+// HttpMatcher.Freeze.
+type FrozenHttpMatcher[V any] struct {
+	trees      map[string]*frozenTree[V]
+	qualified  map[string]map[string]*qualified[V]
+	normalizer *Normalizer
+}
+
+// frozenTree is a tree of nodes flattened into a single slice in breadth-first
+// order, so that the children of a node are stored next to each other.
+type frozenTree[V any] struct {
+	nodes     []frozenNode
+	values    []V
+	static    map[string]int32 // index of the node of every static pattern
+	maxParams int
+}
+
+type frozenNode struct {
+	path      string
+	indices   string
+	fullPath  string
+	table     *[256]uint8 // position+1 of the child for each index byte
+	children  int32       // index of the first child
+	nChildren int32
+	value     int32 // index into values, or noValue
+	nType     nodeType
+	wildChild bool
+}
+
+const noValue = -1
+
+// wideNode is the number of children from which a node gets a lookup table
+// instead of scanning its indices.
+const wideNode = 8
+
+// Freeze returns an immutable snapshot of the matcher for read-only
+// workloads. Its nodes are flattened into a single array, nodes with many
+// children look up the next one in a 256-entry table, and purely static
+// patterns are found in a hash map without walking the tree at all. Lookups
+// return the same results as Find did at the time of the snapshot.
+//
+// Unlike Matcher, the snapshot is safe for concurrent use. Later changes to
+// the matcher don't affect it, except for changes to the Normalizer it points
+// to.
+func (m *Matcher[V]) Freeze() *FrozenMatcher[V] {
+	return &FrozenMatcher[V]{
+		tree:       freezeTree(m.tree, int(m.maxParams)),
+		normalizer: m.Normalizer,
+	}
+}
+
+// Freeze returns an immutable snapshot of the matcher, see Matcher.Freeze.
+// Qualified values are included and selected by FindQualified as before.
+func (m *HttpMatcher[V]) Freeze() *FrozenHttpMatcher[V] {
+	f := &FrozenHttpMatcher[V]{
+		trees:      make(map[string]*frozenTree[V], len(m.trees)),
+		normalizer: m.Normalizer,
+	}
+	for method, tree := range m.trees {
+		f.trees[method] = freezeTree(tree, int(m.maxParams))
+	}
+	if m.qualified != nil {
+		f.qualified = make(map[string]map[string]*qualified[V], len(m.qualified))
+		for method, endpoints := range m.qualified {
+			f.qualified[method] = make(map[string]*qualified[V], len(endpoints))
+			for path, q := range endpoints {
+				f.qualified[method][path] = &qualified[V]{
+					fallback: q.fallback,
+					routes:   append([]*qualifiedRoute[V](nil), q.routes...),
+				}
+			}
+		}
+	}
+	return f
+}
+
+func freezeTree[V any](root *node[V], maxParams int) *frozenTree[V] {
+	t := &frozenTree[V]{
+		static:    make(map[string]int32),
+		maxParams: maxParams,
+	}
+	queue := []*node[V]{root}
+	for i := 0; i < len(queue); i++ {
+		n := queue[i]
+		fn := frozenNode{
+			path:      n.path,
+			indices:   n.indices,
+			fullPath:  n.fullPath,
+			children:  int32(len(queue)),
+			nChildren: int32(len(n.children)),
+			value:     noValue,
+			nType:     n.nType,
+			wildChild: n.wildChild,
+		}
+		queue = append(queue, n.children...)
+
+		if n.value != nil {
+			fn.value = int32(len(t.values))
+			t.values = append(t.values, *n.value)
+			if !strings.ContainsAny(n.fullPath, ":*") {
+				t.static[n.fullPath] = int32(i)
+			}
+		}
+		if !n.wildChild && len(n.children) >= wideNode {
+			fn.table = new([256]uint8)
+			for pos, c := range []byte(n.indices) {
+				fn.table[c] = uint8(pos + 1)
+			}
+		}
+		t.nodes = append(t.nodes, fn)
+	}
+	return t
+}
+
+// child returns the static child of n for the next path byte c, or nil.
+func (t *frozenTree[V]) child(n *frozenNode, c byte) *frozenNode {
+	if n.table != nil {
+		if pos := n.table[c]; pos != 0 {
+			return &t.nodes[n.children+int32(pos)-1]
+		}
+		return nil
+	}
+	for i := 0; i < len(n.indices); i++ {
+		if n.indices[i] == c {
+			return &t.nodes[n.children+int32(i)]
+		}
+	}
+	return nil
+}
+
+// findMatch is node.findMatch for the flattened tree. It returns the node
+// holding the value, or nil.
+func (t *frozenTree[V]) findMatch(path string) (n *frozenNode, ps Params, tsr bool) {
+	if i, ok := t.static[path]; ok {
+		return &t.nodes[i], nil, false
+	}
+
+	n = &t.nodes[0]
+walk:
+	for {
+		prefix := n.path
+		if len(path) > len(prefix) {
+			if path[:len(prefix)] == prefix {
+				path = path[len(prefix):]
+
+				if !n.wildChild {
+					if child := t.child(n, path[0]); child != nil {
+						n = child
+						continue walk
+					}
+					return nil, ps, path == "/" && n.value != noValue
+				}
+
+				n = &t.nodes[n.children]
+				if ps == nil {
+					ps = make(Params, 0, t.maxParams)
+				}
+				switch n.nType {
+				case param:
+					end := 0
+					for end < len(path) && path[end] != '/' {
+						end++
+					}
+					ps = append(ps, Param{Key: n.path[1:], Value: path[:end]})
+
+					if end < len(path) {
+						if n.nChildren > 0 {
+							path = path[end:]
+							n = &t.nodes[n.children]
+							continue walk
+						}
+						return nil, ps, len(path) == end+1
+					}
+
+					if n.value != noValue {
+						return n, ps, false
+					}
+					if n.nChildren == 1 {
+						c := &t.nodes[n.children]
+						tsr = (c.path == "/" && c.value != noValue) || (c.path == "" && c.indices == "/")
+					}
+					return nil, ps, tsr
+
+				case catchAll:
+					ps = append(ps, Param{Key: n.path[2:], Value: path})
+					if n.value == noValue {
+						return nil, ps, false
+					}
+					return n, ps, false
+
+				default:
+					panic("invalid node type")
+				}
+			}
+		} else if path == prefix {
+			if n.value != noValue {
+				return n, ps, false
+			}
+			if path == "/" && n.wildChild && n.nType != root {
+				return nil, ps, true
+			}
+			if path == "/" && n.nType == static {
+				return nil, ps, true
+			}
+			for i, c := range []byte(n.indices) {
+				if c == '/' {
+					c := &t.nodes[n.children+int32(i)]
+					tsr = (len(c.path) == 1 && c.value != noValue) ||
+						(c.nType == catchAll && t.nodes[c.children].value != noValue)
+					return nil, ps, tsr
+				}
+			}
+			return nil, ps, false
+		}
+
+		tsr = (path == "/") ||
+			(len(prefix) == len(path)+1 && prefix[len(path)] == '/' &&
+				path == prefix[:len(prefix)-1] && n.value != noValue)
+		return nil, ps, tsr
+	}
+}
+
+// normalize applies n to path like Matcher.Find. If it changes the path, the
+// lookup is over and done reports whether to recommend a redirect.
+func (t *frozenTree[V]) normalize(n *Normalizer, path string) (done, redir bool) {
+	if n == nil {
+		return false, false
+	}
+	normalized, changed, err := n.Normalize(path)
+	if err != nil {
+		return true, false
+	}
+	if changed {
+		found, _, _ := t.findMatch(normalized)
+		return true, found != nil
+	}
+	return false, false
+}
+
+// Find is Matcher.Find on the snapshot.
+func (f *FrozenMatcher[V]) Find(path string) (match string, value V, params Params, redir bool) {
+	if done, redir := f.tree.normalize(f.normalizer, path); done {
+		return "", value, nil, redir
+	}
+	n, ps, tsr := f.tree.findMatch(path)
+	if n == nil {
+		return "", value, nil, tsr
+	}
+	return n.fullPath, f.tree.values[n.value], ps, false
+}
+
+// Find is HttpMatcher.Find on the snapshot.
+func (f *FrozenHttpMatcher[V]) Find(method, path string) (match string, value V, params Params, redir bool) {
+	match, value, params, redir, _ = f.FindQualified(method, path, nil, nil)
+	return
+}
+
+// FindQualified is HttpMatcher.FindQualified on the snapshot.
+func (f *FrozenHttpMatcher[V]) FindQualified(method, path string, query url.Values, header http.Header) (match string, value V, params Params, redir bool, err error) {
+	tree := f.trees[method]
+	if tree == nil {
+		return
+	}
+	if done, redir := tree.normalize(f.normalizer, path); done {
+		return "", value, nil, redir, nil
+	}
+	n, ps, tsr := tree.findMatch(path)
+	if n == nil {
+		return "", value, nil, tsr, nil
+	}
+	if q := f.qualified[method][n.fullPath]; q != nil {
+		route, qerr := q.find(query, header)
+		if route == nil && !q.fallback {
+			return "", value, nil, false, qerr
+		}
+		if route != nil {
+			var pps *Params
+			if ps != nil {
+				pps = &ps
+			}
+			pps = route.appendParams(pps, query, func() *Params {
+				ps := make(Params, 0, tree.maxParams)
+				return &ps
+			})
+			return n.fullPath, route.value, *pps, false, nil
+		}
+	}
+	return n.fullPath, tree.values[n.value], ps, false, nil
+}
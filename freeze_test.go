@@ -0,0 +1,178 @@
+package pathmatcher
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+var freezeRoutes = []string{
+	"/",
+	"/hi",
+	"/b/",
+	"/contact",
+	"/co",
+	"/c",
+	"/cmd/:tool/:sub",
+	"/cmd/:tool/",
+	"/src/*filepath",
+	"/search/",
+	"/search/:query",
+	"/user_:name",
+	"/user_:name/about",
+	"/files/:dir/*filepath",
+	"/doc",
+	"/doc/go_faq.html",
+	"/doc/go1.html",
+	"/info/:user/public",
+	"/info/:user/project/:project",
+	"/x",
+	"/x/y",
+	"/y/",
+	"/y/z",
+	"/0/:id",
+	"/0/:id/1",
+	"/1/:id/",
+	"/1/:id/2",
+	"/aa",
+	"/a/",
+	"/admin",
+	"/admin/:category",
+	"/admin/:category/:page",
+	"/no/a",
+	"/no/b",
+	"/api/hello/:name",
+	"/vendor/:x/*y",
+	"/α",
+	"/β",
+}
+
+var freezePaths = []string{
+	"", "/", "/hi", "/hi/", "/b", "/b/", "/contact", "/con", "/cona", "/co", "/c", "/no", "/no/", "/_", "/_/",
+	"/cmd/test/", "/cmd/test", "/cmd/test/3", "/cmd/vet", "/src", "/src/", "/src/some/file.png",
+	"/search/", "/search/gopher", "/search/gopher/", "/user_gopher", "/user_gopher/about", "/user_gopher/",
+	"/files/js/inc/framework.js", "/files/js", "/doc", "/doc/", "/doc/go1.html", "/info/gordon/public",
+	"/info/gordon/project/go", "/info/gordon/project/go/", "/x", "/x/", "/x/y", "/y", "/y/", "/y/z",
+	"/0/go", "/0/go/", "/0/go/1", "/1/go", "/1/go/", "/1/go/2", "/a", "/a/", "/aa", "/admin", "/admin/",
+	"/admin/config", "/admin/config/", "/admin/config/permissions", "/admin/config/permissions/",
+	"/api/hello/you", "/api/world/abc", "/vendor/x", "/vendor/x/y/z", "/α", "/β", "/γ",
+}
+
+func TestFreeze(t *testing.T) {
+	m := NewMatcher[string]()
+	wide := NewMatcher[string]()
+	for _, route := range freezeRoutes {
+		m.Add(route, route)
+	}
+	for c := 'a'; c <= 'z'; c++ {
+		wide.Add(fmt.Sprintf("/%c", c), string(c))
+		wide.Add(fmt.Sprintf("/%c/:id", c), string(c)+"id")
+	}
+
+	for _, m := range []*Matcher[string]{m, wide} {
+		f := m.Freeze()
+		for _, path := range append(freezePaths, "/q", "/q/", "/q/1", "/Q") {
+			match, value, params, redir := m.Find(path)
+			fmatch, fvalue, fparams, fredir := f.Find(path)
+			if fmatch != match || fvalue != value || !reflect.DeepEqual(fparams, params) || fredir != redir {
+				t.Errorf("Find(%q): frozen %q, %q, %v, %t; want %q, %q, %v, %t",
+					path, fmatch, fvalue, fparams, fredir, match, value, params, redir)
+			}
+		}
+	}
+}
+
+func TestFreezeHttpMatcher(t *testing.T) {
+	m := NewHttpMatcher[string]()
+	m.Normalizer = &Normalizer{CollapseSlashes: true}
+	m.GET("/users/:id", "user")
+	m.GET("/users", "users")
+	m.POST("/users", "create")
+	m.AddQuery("GET", "/search", url.Values{"q": nil}, "search")
+	m.AddHeader("GET", "/users/:id", http.Header{"Accept": {"text/html"}}, "user-html")
+	f := m.Freeze()
+
+	// Later changes don't affect the snapshot.
+	m.GET("/late", "late")
+
+	tests := []struct {
+		method, path string
+		query        url.Values
+		header       http.Header
+	}{
+		{"GET", "/users/1", nil, nil},
+		{"GET", "/users/1", nil, http.Header{"Accept": {"text/html"}}},
+		{"GET", "/users/1", nil, http.Header{"Accept": {"application/json"}}},
+		{"GET", "//users", nil, nil},
+		{"GET", "/users/", nil, nil},
+		{"POST", "/users", nil, nil},
+		{"PUT", "/users", nil, nil},
+		{"GET", "/search", url.Values{"q": {"go"}}, nil},
+		{"GET", "/search", nil, nil},
+	}
+	for _, test := range tests {
+		match, value, params, redir, err := m.FindQualified(test.method, test.path, test.query, test.header)
+		fmatch, fvalue, fparams, fredir, ferr := f.FindQualified(test.method, test.path, test.query, test.header)
+		if fmatch != match || fvalue != value || !reflect.DeepEqual(fparams, params) || fredir != redir || ferr != err {
+			t.Errorf("FindQualified(%s %s): frozen %q, %q, %v, %t, %v; want %q, %q, %v, %t, %v",
+				test.method, test.path, fmatch, fvalue, fparams, fredir, ferr, match, value, params, redir, err)
+		}
+	}
+	if match, _, _, _ := f.Find("GET", "/late"); match != "" {
+		t.Errorf("route added after Freeze found in snapshot")
+	}
+}
+
+func TestFreezeConcurrent(t *testing.T) {
+	m := NewMatcher[string]()
+	for _, route := range freezeRoutes {
+		m.Add(route, route)
+	}
+	f := m.Freeze()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if _, _, ps, _ := f.Find("/info/gordon/project/go"); ps.ByName("project") != "go" {
+					t.Error("wrong params")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkFreeze(b *testing.B) {
+	m := NewMatcher[string]()
+	for _, route := range freezeRoutes {
+		m.Add(route, route)
+	}
+	for c := 'a'; c <= 'z'; c++ {
+		m.Add(fmt.Sprintf("/wide/%c/:id", c), string(c))
+	}
+	f := m.Freeze()
+	// Static paths are found in a map by both, so only benchmark params
+	paths := []string{"/cmd/test/vet", "/wide/z/1", "/info/gordon/project/go", "/src/some/file.png"}
+
+	for _, path := range paths {
+		b.Run("Matcher"+path, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				m.Find(path)
+			}
+		})
+		b.Run("Frozen"+path, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				f.Find(path)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package pathmatcher
+
+import (
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// FuzzTree adds a set of newline separated patterns to a matcher. Every
+// pattern either panics with a conflict, or is found afterwards for a path
+// built from it, with the params it was built with.
+func FuzzTree(f *testing.F) {
+	f.Add("/\n/cmd/:tool/:sub\n/cmd/:tool/\n/src/*filepath")
+	f.Add("/search/\n/search/:query\n/user_:name\n/user_:name/about")
+	f.Add("/files/:dir/*filepath\n/doc/\n/doc/go_faq.html\n/doc/go1.html")
+	f.Add("/info/:user/public\n/info/:user/project/:project")
+	f.Add("/a/:b\n/a/c")
+	f.Add("/:a\n/*b")
+
+	f.Fuzz(func(t *testing.T, input string) {
+		patterns := strings.Split(input, "\n")
+		if len(patterns) > 32 || len(input) > 1024 {
+			t.Skip()
+		}
+
+		var added []string
+		m := NewMatcher[string]()
+		for _, pattern := range patterns {
+			if recv := catchPanic(func() { m.Add(pattern, pattern) }); recv != nil {
+				// A panic may leave the tree half updated, so start over
+				m = NewMatcher[string]()
+				for _, pattern := range added {
+					m.Add(pattern, pattern)
+				}
+				continue
+			}
+			added = append(added, pattern)
+		}
+
+		for _, pattern := range added {
+			path, params := fuzzPath(pattern)
+			match, value, ps, _ := m.Find(path)
+			if len(ps) == 0 {
+				ps = nil
+			}
+			if match != pattern || value != pattern || !reflect.DeepEqual(ps, params) {
+				t.Errorf("Find(%q) = %q, %q, %v; want %q, %v", path, match, value, ps, pattern, params)
+			}
+		}
+	})
+}
+
+// fuzzPath returns a path matching pattern and the params it matches with.
+func fuzzPath(pattern string) (string, Params) {
+	var b strings.Builder
+	var params Params
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		if c != ':' && c != '*' {
+			b.WriteByte(c)
+			continue
+		}
+		end := i + 1
+		for end < len(pattern) && pattern[end] != '/' {
+			end++
+		}
+		value := "v" + strconv.Itoa(len(params))
+		b.WriteString(value)
+		if c == '*' {
+			// The catch-all value includes the slash before it
+			value = "/" + value
+		}
+		params = append(params, Param{Key: pattern[i+1 : end], Value: value})
+		i = end - 1
+	}
+	return b.String(), params
+}
+
+// FuzzCaseInsensitivePath checks that findCaseInsensitivePath agrees with
+// Find: a path found by Find is its own case-insensitive match, and every path
+// returned by the case-insensitive lookup is found by Find. When fixing the
+// trailing slash, the lookup inherits the trailing slash recommendation of
+// Find, which isn't always found either, e.g. for an empty param before it.
+func FuzzCaseInsensitivePath(f *testing.F) {
+	m := NewMatcher[string]()
+	for _, route := range freezeRoutes {
+		m.Add(route, route)
+	}
+	for _, path := range freezePaths {
+		f.Add(path, false)
+	}
+	f.Add("/DOC/GO1.HTML", true)
+	f.Add("/Info/Gordon/Project/Go/", true)
+	f.Add("/ΑΒ", false)
+
+	f.Fuzz(func(t *testing.T, path string, fixTrailingSlash bool) {
+		if len(path) == 0 || path[0] != '/' {
+			t.Skip()
+		}
+		match, _, _, _ := m.Find(path)
+		ciPath, found := m.tree.findCaseInsensitivePath(path, fixTrailingSlash)
+		if match != "" && (!found || ciPath != path) {
+			t.Errorf("findCaseInsensitivePath(%q) = %q, %t for a path found by Find", path, ciPath, found)
+		}
+		if !found {
+			return
+		}
+		if cimatch, _, _, _ := m.Find(ciPath); cimatch != "" {
+			return
+		}
+		if fixTrailingSlash {
+			// The fixed case with the trailing slash of the original path;
+			// changing the case doesn't change the number of runes.
+			var unfixed string
+			switch n, ciN := utf8.RuneCountInString(path), utf8.RuneCountInString(ciPath); {
+			case ciN == n-1:
+				unfixed = ciPath + "/"
+			case ciN == n+1:
+				unfixed = ciPath[:len(ciPath)-1]
+			}
+			if _, _, _, tsr := m.Find(unfixed); unfixed != "" && tsr {
+				return
+			}
+		}
+		t.Errorf("findCaseInsensitivePath(%q) = %q, which Find doesn't find", path, ciPath)
+	})
+}
+
+// FuzzCleanPath checks that CleanPath is idempotent, agrees with
+// AppendCleanPath and CleanPathBytes, and is equivalent to path.Clean of the
+// rooted path, except that it keeps a trailing slash.
+func FuzzCleanPath(f *testing.F) {
+	for _, test := range cleanTests {
+		f.Add(test.path)
+	}
+
+	f.Fuzz(func(t *testing.T, p string) {
+		clean := CleanPath(p)
+		if again := CleanPath(clean); again != clean {
+			t.Errorf("CleanPath(%q) = %q, but CleanPath(%q) = %q", p, clean, clean, again)
+		}
+		if appended := string(AppendCleanPath([]byte("x"), p)); appended != "x"+clean {
+			t.Errorf("AppendCleanPath(x, %q) = %q, want %q", p, appended, "x"+clean)
+		}
+		if bytes := string(CleanPathBytes([]byte(p))); bytes != clean {
+			t.Errorf("CleanPathBytes(%q) = %q, want %q", p, bytes, clean)
+		}
+
+		want := path.Clean("/" + p)
+		switch p[strings.LastIndexByte(p, '/')+1:] {
+		case "", ".":
+			if want != "/" {
+				want += "/"
+			}
+		}
+		if clean != want {
+			t.Errorf("CleanPath(%q) = %q, want %q", p, clean, want)
+		}
+	})
+}
@@ -2,4 +2,7 @@ module github.com/infogulch/pathmatcher
 
 go 1.21
 
-require golang.org/x/exp v0.0.0-20230905200255-921286631fa9
+require (
+	golang.org/x/exp v0.0.0-20230905200255-921286631fa9
+	golang.org/x/text v0.13.0
+)
@@ -0,0 +1,138 @@
+package pathmatcher
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNotAcceptable is returned by FindQualified if the endpoint exists,
+	// but none of its values produces a media type the request accepts. A
+	// router would answer 406 Not Acceptable.
+	ErrNotAcceptable = errors.New("pathmatcher: no acceptable media type")
+
+	// ErrUnsupportedMediaType is returned by FindQualified if the endpoint
+	// exists, but none of its values consumes the media type of the request
+	// body. A router would answer 415 Unsupported Media Type.
+	ErrUnsupportedMediaType = errors.New("pathmatcher: unsupported media type")
+)
+
+// AddHeader adds an endpoint like Add, but qualified by request headers. Two
+// headers are negotiated rather than compared:
+//
+//   - Accept lists the media types the value produces. The request's Accept
+//     header must accept one of them; a request without Accept accepts all.
+//   - Content-Type lists the media types the value consumes. The media type of
+//     the request's Content-Type must be one of them, or match one of the form
+//     "type/*".
+//
+// Any other header, such as a custom API-Version, must be present in the
+// request; for every value listed under it, the request must carry that value.
+//
+// Qualified values are selected by FindQualified after the path has matched,
+// in the same order of specificity as for AddQuery. Among equally specific
+// values, the one whose media type the client prefers wins.
+func (m *HttpMatcher[V]) AddHeader(method, path string, header http.Header, value V) {
+	if len(header) == 0 {
+		panic("header must not be empty for path '" + path + "'")
+	}
+	m.AddQualified(method, path, nil, header, value)
+}
+
+// FindQualified is like Find, but selects between qualified values of the
+// matched endpoint by the request's query and header, see AddQuery and
+// AddHeader.
+//
+// If the endpoint exists but none of its values is satisfied and there is no
+// unqualified fallback, err distinguishes a media type mismatch from a miss:
+// ErrUnsupportedMediaType if some value was only ruled out by Content-Type,
+// otherwise ErrNotAcceptable if some value was only ruled out by Accept.
+func (m *HttpMatcher[V]) FindQualified(method, path string, query url.Values, header http.Header) (match string, value V, params Params, redir bool, err error) {
+	match, value, params, redir, err = m.lookup(m.Normalizer, method, path, query, header)
+	m.Stats.record(method, match, redir)
+	return
+}
+
+func (r *qualifiedRoute[V]) matchesHeader(header http.Header) bool {
+	for key, want := range r.header {
+		if key == "Accept" || key == "Content-Type" {
+			continue
+		}
+		have, ok := header[key]
+		if !ok {
+			return false
+		}
+		for _, w := range want {
+			if !contains(have, w) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (r *qualifiedRoute[V]) matchesContentType(header http.Header) bool {
+	consumes, ok := r.header["Content-Type"]
+	if !ok {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	for _, c := range consumes {
+		if mediaTypeMatches(c, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptQuality returns the highest quality with which the request accepts
+// one of the media types produced by the route, or 0 if it accepts none.
+func (r *qualifiedRoute[V]) acceptQuality(header http.Header) float64 {
+	produces, ok := r.header["Accept"]
+	if !ok {
+		return 1
+	}
+	accept := header.Values("Accept")
+	if len(accept) == 0 {
+		return 1
+	}
+
+	var best float64
+	for _, line := range accept {
+		for _, part := range strings.Split(line, ",") {
+			mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+			quality := 1.0
+			if q, ok := params["q"]; ok {
+				if quality, err = strconv.ParseFloat(q, 64); err != nil {
+					continue
+				}
+			}
+			for _, p := range produces {
+				if quality > best && mediaTypeMatches(mediaRange, p) {
+					best = quality
+				}
+			}
+		}
+	}
+	return best
+}
+
+// mediaTypeMatches reports whether the media type t falls in mediaRange,
+// which may be a media type, "type/*" or "*/*".
+func mediaTypeMatches(mediaRange, t string) bool {
+	if mediaRange == "*/*" || strings.EqualFold(mediaRange, t) {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(mediaRange, "/*")
+	return ok && len(t) > len(prefix) && strings.EqualFold(t[:len(prefix)+1], prefix+"/")
+}
@@ -0,0 +1,67 @@
+package pathmatcher
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestFindQualifiedHeader(t *testing.T) {
+	m := NewHttpMatcher[string]()
+	m.AddHeader("GET", "/pets/:id", http.Header{"Accept": {"application/json"}}, "json")
+	m.AddHeader("GET", "/pets/:id", http.Header{"Accept": {"text/html"}}, "html")
+	m.AddHeader("GET", "/pets/:id", http.Header{"Accept": {"application/json"}, "api-version": {"2"}}, "json-v2")
+	m.AddHeader("POST", "/pets", http.Header{"Content-Type": {"application/json"}}, "create-json")
+	m.AddHeader("POST", "/pets", http.Header{"Content-Type": {"multipart/*"}}, "create-multipart")
+	m.AddHeader("PUT", "/pets/:id", http.Header{"Accept": {"application/json"}}, "put-json")
+	m.PUT("/pets/:id", "put")
+
+	tests := []struct {
+		method, path string
+		header       http.Header
+		value        string
+		err          error
+	}{
+		{"GET", "/pets/1", http.Header{"Accept": {"application/json"}}, "json", nil},
+		{"GET", "/pets/1", http.Header{"Accept": {"text/html,application/xhtml+xml"}}, "html", nil},
+		{"GET", "/pets/1", http.Header{"Accept": {"text/html;q=0.5, application/*"}}, "json", nil},
+		{"GET", "/pets/1", http.Header{"Accept": {"text/html", "application/json;q=0.9"}}, "html", nil},
+		{"GET", "/pets/1", http.Header{"Accept": {"*/*"}, "Api-Version": {"2"}}, "json-v2", nil},
+		{"GET", "/pets/1", http.Header{"Accept": {"text/html"}, "Api-Version": {"2"}}, "html", nil},
+		{"GET", "/pets/1", http.Header{}, "json", nil},
+		{"GET", "/pets/1", http.Header{"Accept": {"image/png"}}, "", ErrNotAcceptable},
+		{"GET", "/pets/1", http.Header{"Accept": {"application/json;q=0"}}, "", ErrNotAcceptable},
+		{"GET", "/cats/1", http.Header{"Accept": {"image/png"}}, "", nil},
+		{"POST", "/pets", http.Header{"Content-Type": {"application/json; charset=utf-8"}}, "create-json", nil},
+		{"POST", "/pets", http.Header{"Content-Type": {"multipart/form-data; boundary=x"}}, "create-multipart", nil},
+		{"POST", "/pets", http.Header{"Content-Type": {"text/plain"}}, "", ErrUnsupportedMediaType},
+		{"POST", "/pets", http.Header{}, "", ErrUnsupportedMediaType},
+		{"PUT", "/pets/1", http.Header{"Accept": {"application/json"}}, "put-json", nil},
+		{"PUT", "/pets/1", http.Header{"Accept": {"image/png"}}, "put", nil},
+	}
+	for _, test := range tests {
+		_, value, _, _, err := m.FindQualified(test.method, test.path, nil, test.header)
+		if value != test.value || err != test.err {
+			t.Errorf("FindQualified(%s, %s, %v) = %q, %v; want %q, %v",
+				test.method, test.path, test.header, value, err, test.value, test.err)
+		}
+	}
+}
+
+func TestMediaTypeMatches(t *testing.T) {
+	tests := []struct {
+		mediaRange, t string
+		match         bool
+	}{
+		{"*/*", "text/html", true},
+		{"text/*", "text/html", true},
+		{"TEXT/HTML", "text/html", true},
+		{"text/*", "textual/html", false},
+		{"text/*", "text", false},
+		{"application/json", "application/json+x", false},
+	}
+	for _, test := range tests {
+		if match := mediaTypeMatches(test.mediaRange, test.t); match != test.match {
+			t.Errorf("mediaTypeMatches(%q, %q) = %t, want %t", test.mediaRange, test.t, match, test.match)
+		}
+	}
+}
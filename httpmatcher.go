@@ -3,6 +3,7 @@ package pathmatcher
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"sync"
 
@@ -17,6 +18,30 @@ type HttpMatcher[V any] struct {
 
 	paramsPool sync.Pool
 	maxParams  uint
+	meta       map[string]map[string]Meta
+	qualified  map[string]map[string]*qualified[V]
+	hosts      map[string]*HttpMatcher[V]
+	static     map[string]map[string]*V // values of static patterns by method
+
+	// Normalizer, if set, is applied to paths before lookup. See
+	// Matcher.Normalizer.
+	Normalizer *Normalizer
+
+	// UseRawPath makes FindURL match the escaped path of the URL with
+	// FindEscaped instead of the decoded path.
+	UseRawPath bool
+
+	// EscapedParams makes FindEscaped return param values with "%2F" and
+	// "%25" still escaped instead of fully decoded.
+	EscapedParams bool
+
+	// MethodFallbacks maps a method to the method FindRequest looks up
+	// instead if nothing matches, e.g. {"HEAD": "GET"}.
+	MethodFallbacks map[string]string
+
+	// Stats, if set, counts the lookups of the matcher by method and matched
+	// pattern. Lookups answered by a Host matcher are counted by its own Stats.
+	Stats *Stats
 }
 
 func (r *HttpMatcher[V]) getParams() *Params {
@@ -58,14 +83,62 @@ func (m *HttpMatcher[V]) Add(method, path string, value V) {
 		panic(fmt.Sprintf("invalid method '%s'", method))
 	}
 
+	// The path may already hold a placeholder for qualified values only
+	if q := m.qualified[method][path]; q != nil && !q.fallback {
+		*q.slot = value
+		q.fallback = true
+		return
+	}
+
+	m.add(method, path, &value)
+}
+
+func (m *HttpMatcher[V]) add(method, path string, value *V) {
 	tree, ok := m.trees[method]
 	if !ok {
 		tree = &node[V]{}
 		m.trees[method] = tree
 	}
 
-	tree.addPath(path, &value)
+	tree.addPath(path, value)
 	m.maxParams = max(m.maxParams, countParams(path))
+	if !strings.ContainsAny(path, ":*") {
+		if m.static == nil {
+			m.static = make(map[string]map[string]*V)
+		}
+		if m.static[method] == nil {
+			m.static[method] = make(map[string]*V)
+		}
+		m.static[method][path] = value
+	}
+}
+
+// AddMeta adds an endpoint like Add and attaches meta to it. The metadata can
+// be read back with Meta using the pattern returned by Find, and is reported by
+// Walk and Export.
+func (m *HttpMatcher[V]) AddMeta(method, path string, value V, meta Meta) {
+	m.Add(method, path, value)
+	m.setMeta(method, path, meta)
+}
+
+// setMeta attaches meta to the endpoint, unless it is nil.
+func (m *HttpMatcher[V]) setMeta(method, path string, meta Meta) {
+	if meta != nil {
+		if m.meta == nil {
+			m.meta = make(map[string]map[string]Meta)
+		}
+		if m.meta[method] == nil {
+			m.meta[method] = make(map[string]Meta)
+		}
+		m.meta[method][path] = meta
+	}
+}
+
+// Meta returns the metadata attached to the registered method and pattern,
+// usually the match returned by Find. It returns nil if the endpoint has no
+// metadata.
+func (m *HttpMatcher[V]) Meta(method, pattern string) Meta {
+	return m.meta[method][pattern]
 }
 
 func (m *HttpMatcher[V]) GET(path string, value V)     { m.Add(http.MethodGet, path, value) }
@@ -79,17 +152,57 @@ func (m *HttpMatcher[V]) CONNECT(path string, value V) { m.Add(http.MethodConnec
 func (m *HttpMatcher[V]) OPTIONS(path string, value V) { m.Add(http.MethodOptions, path, value) }
 
 func (m *HttpMatcher[V]) Find(method, path string) (match string, value V, params Params, redir bool) {
+	match, value, params, redir, _ = m.lookup(m.Normalizer, method, path, nil, nil)
+	m.Stats.record(method, match, redir)
+	return
+}
+
+// lookup implements Find, FindQuery and FindQualified. The path is normalized
+// with n, if set. If the matched endpoint has qualified values, the most
+// specific one satisfied by query and header is chosen.
+func (m *HttpMatcher[V]) lookup(n *Normalizer, method, path string, query url.Values, header http.Header) (match string, value V, params Params, redir bool, err error) {
 	tree, ok := m.trees[method]
 	if !ok {
 		return
 	}
+	if n != nil {
+		normalized, changed, nerr := n.Normalize(path)
+		if nerr != nil {
+			return
+		}
+		if changed {
+			pvalue, pparams, _, _ := tree.findMatch(normalized, m.getParams)
+			m.putParams(pparams)
+			redir = pvalue != nil
+			return
+		}
+	}
 	var pvalue *V
 	var pparams *Params
-	pvalue, pparams, match, redir = tree.findMatch(path, m.getParams)
+	// A static pattern only matches itself, so the tree walk can be skipped
+	if v, ok := m.static[method][path]; ok {
+		pvalue, match = v, path
+	} else {
+		pvalue, pparams, match, redir = tree.findMatch(path, m.getParams)
+	}
 	if pvalue == nil {
 		m.putParams(pparams)
 		return
 	}
+	if q := m.qualified[method][match]; q != nil {
+		var route *qualifiedRoute[V]
+		route, err = q.find(query, header)
+		if route == nil && !q.fallback {
+			m.putParams(pparams)
+			match = ""
+			return
+		}
+		err = nil
+		if route != nil {
+			pvalue = &route.value
+			pparams = route.appendParams(pparams, query, m.getParams)
+		}
+	}
 	if pparams != nil {
 		params = *pparams
 	}
@@ -97,6 +210,35 @@ func (m *HttpMatcher[V]) Find(method, path string) (match string, value V, param
 	return
 }
 
+// Walk calls fn for every registered route, ordered by method name and then
+// in tree order. Qualified values follow the unqualified value of the same
+// endpoint, most specific first, and share its metadata.
+func (m *HttpMatcher[V]) Walk(fn func(route Route[V])) {
+	methods := make([]string, 0, len(m.trees))
+	for method := range m.trees {
+		methods = append(methods, method)
+	}
+	slices.Sort(methods)
+
+	for _, method := range methods {
+		m.trees[method].walk(func(n *node[V]) {
+			if n.value == nil {
+				return
+			}
+			meta := m.meta[method][n.fullPath]
+			q := m.qualified[method][n.fullPath]
+			if q == nil || q.fallback {
+				fn(Route[V]{Method: method, Path: n.fullPath, Value: *n.value, Meta: meta})
+			}
+			if q != nil {
+				for _, route := range q.routes {
+					fn(Route[V]{Method: method, Path: n.fullPath, Value: route.value, Meta: meta, Query: route.query, Header: route.header})
+				}
+			}
+		})
+	}
+}
+
 // Allowed returns an Allow list [1] based on the methods and endpoints set in
 // the matcher.
 //
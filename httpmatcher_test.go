@@ -2,6 +2,8 @@ package pathmatcher
 
 import (
 	"net/http"
+	"net/url"
+	"reflect"
 	"testing"
 )
 
@@ -65,3 +67,63 @@ func TestAllowed(t *testing.T) {
 		}
 	}
 }
+
+func TestHttpMatcherMeta(t *testing.T) {
+	m := NewHttpMatcher[int]()
+	m.AddMeta(http.MethodGet, "/users/:id", 1, Meta{"rate": "low"})
+	m.AddMeta(http.MethodDelete, "/users/:id", 2, Meta{"rate": "high"})
+
+	if meta := m.Meta(http.MethodDelete, "/users/:id"); meta["rate"] != "high" {
+		t.Errorf("wrong meta: %+v", meta)
+	}
+	if meta := m.Meta(http.MethodPost, "/users/:id"); meta != nil {
+		t.Errorf("expected no meta, got %+v", meta)
+	}
+
+	var rates []any
+	m.Walk(func(route Route[int]) {
+		rates = append(rates, route.Meta["rate"])
+	})
+	if len(rates) != 2 || rates[0] != "high" || rates[1] != "low" {
+		t.Errorf("wrong meta walked: %v", rates)
+	}
+}
+
+func TestHttpMatcherStatic(t *testing.T) {
+	m := NewHttpMatcher[string]()
+	for _, r := range githubAPI {
+		m.Add(r.method, r.path, r.path)
+	}
+	// Static patterns that need a trailing slash redirect or that are a
+	// qualified placeholder only must behave the same as in the tree.
+	m.GET("/slash/", "/slash/")
+	m.AddQuery("GET", "/qualified", url.Values{"q": nil}, "/qualified")
+
+	paths := []string{"/slash", "/slash/", "/user/", "/qualified", "/users/", "/markdown", "/nope"}
+	for _, r := range githubAPI {
+		paths = append(paths, requestPath(r.path))
+	}
+	for _, method := range []string{"GET", "POST", "PUT", "DELETE"} {
+		for _, path := range paths {
+			match, value, params, redir := m.Find(method, path)
+
+			var wmatch, wvalue string
+			var wparams Params
+			var wredir bool
+			if tree := m.trees[method]; tree != nil {
+				pvalue, ps, pmatch, tsr := tree.findMatch(path, m.getParams)
+				wredir = tsr
+				if pvalue != nil && m.qualified[method][pmatch] == nil {
+					wmatch, wvalue = pmatch, *pvalue
+					if ps != nil {
+						wparams = *ps
+					}
+				}
+			}
+			if match != wmatch || value != wvalue || !reflect.DeepEqual(params, wparams) || redir != wredir {
+				t.Errorf("Find(%s, %s) = %q, %q, %v, %t; tree walk %q, %q, %v, %t",
+					method, path, match, value, params, redir, wmatch, wvalue, wparams, wredir)
+			}
+		}
+	}
+}
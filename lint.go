@@ -0,0 +1,192 @@
+package pathmatcher
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LintEntry is a single route to check with Lint. Method is empty for routes
+// meant for a plain Matcher.
+type LintEntry struct {
+	Line   int
+	Method string
+	Path   string
+}
+
+func (e LintEntry) String() string {
+	if e.Method == "" {
+		return e.Path
+	}
+	return e.Method + " " + e.Path
+}
+
+// LintKind classifies a problem found by Lint.
+type LintKind uint8
+
+const (
+	LintInvalidMethod   LintKind = iota // the method is not a known HTTP method
+	LintInvalidPath                     // the path does not begin with '/'
+	LintInvalidWildcard                 // a wildcard is malformed or misplaced
+	LintDuplicate                       // the same method and path were listed before
+	LintConflict                        // the path conflicts with an earlier route
+	LintUnreachable                     // no cleaned request path can reach the route
+)
+
+func (k LintKind) String() string {
+	switch k {
+	case LintInvalidMethod:
+		return "invalid method"
+	case LintInvalidPath:
+		return "invalid path"
+	case LintInvalidWildcard:
+		return "invalid wildcard"
+	case LintDuplicate:
+		return "duplicate"
+	case LintConflict:
+		return "conflict"
+	case LintUnreachable:
+		return "unreachable"
+	default:
+		return fmt.Sprintf("LintKind(%d)", uint8(k))
+	}
+}
+
+// LintIssue is a problem found by Lint.
+type LintIssue struct {
+	Entry   LintEntry
+	Kind    LintKind
+	Message string
+}
+
+func (i LintIssue) String() string {
+	return fmt.Sprintf("line %d: %s: %s", i.Entry.Line, i.Kind, i.Message)
+}
+
+// ParseLintEntries reads routes for Lint, one per line, either as
+// "METHOD /path" or just "/path". Blank lines and lines starting with '#' are
+// skipped. Line numbers are recorded in the entries.
+func ParseLintEntries(r io.Reader) ([]LintEntry, error) {
+	var entries []LintEntry
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text[0] == '#' {
+			continue
+		}
+		fields := strings.Fields(text)
+		switch len(fields) {
+		case 1:
+			entries = append(entries, LintEntry{Line: line, Path: fields[0]})
+		case 2:
+			entries = append(entries, LintEntry{Line: line, Method: fields[0], Path: fields[1]})
+		default:
+			return nil, fmt.Errorf("line %d: expected 'METHOD /path' or '/path', got '%s'", line, text)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// Lint checks a whole route table at once and reports every problem that
+// registering the routes in order would run into, instead of stopping at the
+// first panic. Routes with an empty method are checked as a separate table, as
+// for a plain Matcher.
+//
+// Routes whose path would be rewritten by CleanPath are reported as
+// unreachable, since a router cleaning request paths never looks them up.
+func Lint(entries []LintEntry) []LintIssue {
+	var issues []LintIssue
+	report := func(e LintEntry, kind LintKind, format string, args ...any) {
+		issues = append(issues, LintIssue{Entry: e, Kind: kind, Message: fmt.Sprintf(format, args...)})
+	}
+
+	seen := make(map[LintEntry]int)
+	trees := make(map[string]*lintTree)
+
+	for _, e := range entries {
+		if e.Method != "" && !methodValid(e.Method) {
+			report(e, LintInvalidMethod, "'%s' is not a valid method", e.Method)
+			continue
+		}
+		if len(e.Path) < 1 || e.Path[0] != '/' {
+			report(e, LintInvalidPath, "path must begin with '/' in path '%s'", e.Path)
+			continue
+		}
+		if err := checkWildcards(e.Path); err != "" {
+			report(e, LintInvalidWildcard, "%s", err)
+			continue
+		}
+
+		key := LintEntry{Method: e.Method, Path: e.Path}
+		if line, ok := seen[key]; ok {
+			report(e, LintDuplicate, "'%s' is already registered on line %d", e, line)
+			continue
+		}
+		seen[key] = e.Line
+
+		if clean := CleanPath(e.Path); clean != e.Path {
+			report(e, LintUnreachable, "requests for '%s' are cleaned to '%s'", e.Path, clean)
+		}
+
+		tree, ok := trees[e.Method]
+		if !ok {
+			tree = &lintTree{node: &node[int]{}}
+			trees[e.Method] = tree
+		}
+		if err := tree.add(e.Path); err != nil {
+			report(e, LintConflict, "%v", err)
+		}
+	}
+	return issues
+}
+
+// checkWildcards validates the wildcards of path the same way insertChild
+// does, but without depending on the routes already in the tree.
+func checkWildcards(path string) string {
+	for offset := 0; ; {
+		wildcard, i, valid := findWildcard(path[offset:])
+		if i < 0 {
+			return ""
+		}
+		i += offset
+
+		if !valid {
+			return "only one wildcard per path segment is allowed, has: '" +
+				wildcard + "' in path '" + path + "'"
+		}
+		if len(wildcard) < 2 {
+			return "wildcards must be named with a non-empty name in path '" + path + "'"
+		}
+		if wildcard[0] == '*' {
+			if i+len(wildcard) != len(path) {
+				return "catch-all routes are only allowed at the end of the path in path '" + path + "'"
+			}
+			if path[i-1] != '/' {
+				return "no / before catch-all in path '" + path + "'"
+			}
+		}
+		offset = i + len(wildcard)
+	}
+}
+
+// lintTree is a tree that can recover from a failed insertion. The tree is
+// left in an inconsistent state when addPath panics, so it is rebuilt from the
+// routes added successfully so far.
+type lintTree struct {
+	node  *node[int]
+	paths []string
+}
+
+func (t *lintTree) add(path string) error {
+	err := catchAdd(func() { t.node.addPath(path, new(int)) })
+	if err != nil {
+		t.node = &node[int]{}
+		for _, path := range t.paths {
+			t.node.addPath(path, new(int))
+		}
+		return err
+	}
+	t.paths = append(t.paths, path)
+	return nil
+}
@@ -0,0 +1,80 @@
+package pathmatcher
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	input := `# routes
+GET /users/:id
+GET /users/new
+POST /users
+
+GET /users/:id
+FETCH /users
+GET users
+GET /src/*filepath/x
+GET /:foo:bar
+/plain/:id
+/plain/:id
+GET /docs//index
+GET /cmd/:tool/:sub
+GET /cmd/vet
+GET /users/:id/posts
+`
+	entries, err := ParseLintEntries(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type issue struct {
+		line int
+		kind LintKind
+	}
+	var got []issue
+	for _, i := range Lint(entries) {
+		got = append(got, issue{i.Entry.Line, i.Kind})
+		if i.Message == "" {
+			t.Errorf("empty message for %s", i)
+		}
+	}
+	want := []issue{
+		{3, LintConflict},
+		{6, LintDuplicate},
+		{7, LintInvalidMethod},
+		{8, LintInvalidPath},
+		{9, LintInvalidWildcard},
+		{10, LintInvalidWildcard},
+		{12, LintDuplicate},
+		{13, LintUnreachable},
+		{15, LintConflict},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong issues:\ngot  %v\nwant %v", got, want)
+	}
+}
+
+func TestLintRecoversAfterConflict(t *testing.T) {
+	// The tree is rebuilt after each conflict, so later valid routes that
+	// share a prefix with the conflicting one are still checked correctly.
+	entries := []LintEntry{
+		{Line: 1, Method: "GET", Path: "/a/:b"},
+		{Line: 2, Method: "GET", Path: "/a/c"},
+		{Line: 3, Method: "GET", Path: "/a/:b/c"},
+		{Line: 4, Method: "GET", Path: "/a/:b/d"},
+		{Line: 5, Method: "GET", Path: "/a/:x/e"},
+	}
+	issues := Lint(entries)
+	if len(issues) != 2 || issues[0].Entry.Line != 2 || issues[1].Entry.Line != 5 {
+		t.Errorf("wrong issues: %v", issues)
+	}
+}
+
+func TestParseLintEntriesError(t *testing.T) {
+	_, err := ParseLintEntries(strings.NewReader("GET /a\nGET /b extra\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Errorf("expected error on line 2, got %v", err)
+	}
+}
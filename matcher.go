@@ -1,6 +1,9 @@
 package pathmatcher
 
 import (
+	"net/http"
+	"net/url"
+	"strings"
 	"sync"
 )
 
@@ -12,6 +15,25 @@ type Matcher[V any] struct {
 	tree       *node[V]
 	paramsPool sync.Pool
 	maxParams  uint
+	meta       map[string]Meta
+	static     map[string]*V // values of static patterns
+
+	// Normalizer, if set, is applied to paths before lookup. If it changes a
+	// path that matches once normalized, Find returns no value and recommends
+	// a redirect instead, the same way as for trailing slashes. Paths it
+	// rejects are not found.
+	Normalizer *Normalizer
+
+	// UseRawPath makes FindURL match the escaped path of the URL with
+	// FindEscaped instead of the decoded path.
+	UseRawPath bool
+
+	// EscapedParams makes FindEscaped return param values with "%2F" and
+	// "%25" still escaped instead of fully decoded.
+	EscapedParams bool
+
+	// Stats, if set, counts the lookups of the matcher by matched pattern.
+	Stats *Stats
 }
 
 func (r *Matcher[V]) getParams() *Params {
@@ -48,9 +70,58 @@ func (m *Matcher[V]) Add(path string, value V) {
 	m.tree.addPath(path, &value)
 
 	m.maxParams = max(m.maxParams, countParams(path))
+	if !strings.ContainsAny(path, ":*") {
+		if m.static == nil {
+			m.static = make(map[string]*V)
+		}
+		m.static[path] = &value
+	}
+}
+
+// AddMeta adds path like Add and attaches meta to it. The metadata can be read
+// back with Meta using the pattern returned by Find, and is reported by Walk
+// and Export.
+func (m *Matcher[V]) AddMeta(path string, value V, meta Meta) {
+	m.Add(path, value)
+	if meta != nil {
+		if m.meta == nil {
+			m.meta = make(map[string]Meta)
+		}
+		m.meta[path] = meta
+	}
+}
+
+// Meta returns the metadata attached to the registered pattern, usually the
+// match returned by Find. It returns nil if the pattern has no metadata.
+func (m *Matcher[V]) Meta(pattern string) Meta {
+	return m.meta[pattern]
 }
 
 func (m *Matcher[V]) Find(path string) (match string, value V, params Params, redir bool) {
+	match, value, params, redir = m.find(path)
+	m.Stats.record("", match, redir)
+	return
+}
+
+func (m *Matcher[V]) find(path string) (match string, value V, params Params, redir bool) {
+	if m.Normalizer != nil {
+		normalized, changed, err := m.Normalizer.Normalize(path)
+		if err != nil {
+			return
+		}
+		if changed {
+			pvalue, pparams, _, _ := m.tree.findMatch(normalized, m.getParams)
+			m.putParams(pparams)
+			redir = pvalue != nil
+			return
+		}
+	}
+
+	// A static pattern only matches itself, so the tree walk can be skipped
+	if v, ok := m.static[path]; ok {
+		return path, *v, nil, false
+	}
+
 	var pvalue *V
 	var pparams *Params
 	pvalue, pparams, match, redir = m.tree.findMatch(path, m.getParams)
@@ -64,3 +135,27 @@ func (m *Matcher[V]) Find(path string) (match string, value V, params Params, re
 	value = *pvalue
 	return
 }
+
+// Meta is arbitrary metadata attached to a route, such as tags, auth scopes, a
+// rate-limit class or a deprecation notice. It is kept next to the route's
+// value rather than inside it, so tooling can inspect it without knowing V.
+type Meta map[string]any
+
+// Route describes a single path registered in a matcher, as reported by Walk.
+type Route[V any] struct {
+	Method string // empty for routes of a plain Matcher
+	Path   string
+	Value  V
+	Meta   Meta
+	Query  url.Values  // required query parameters, see HttpMatcher.AddQuery
+	Header http.Header // required headers, see HttpMatcher.AddHeader
+}
+
+// Walk calls fn for every registered route in tree order.
+func (m *Matcher[V]) Walk(fn func(route Route[V])) {
+	m.tree.walk(func(n *node[V]) {
+		if n.value != nil {
+			fn(Route[V]{Path: n.fullPath, Value: *n.value, Meta: m.meta[n.fullPath]})
+		}
+	})
+}
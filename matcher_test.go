@@ -42,3 +42,29 @@ func TestMatcher(t *testing.T) {
 		}
 	}
 }
+
+func TestMatcherMeta(t *testing.T) {
+	m := NewMatcher[int]()
+	m.AddMeta("/users/:id", 1, Meta{"scopes": []string{"users:read"}, "deprecated": true})
+	m.Add("/health", 2)
+
+	match, _, _, _ := m.Find("/users/42")
+	if meta := m.Meta(match); meta["deprecated"] != true {
+		t.Errorf("wrong meta for '%s': %+v", match, meta)
+	}
+	if meta := m.Meta("/health"); meta != nil {
+		t.Errorf("expected no meta for '/health', got %+v", meta)
+	}
+
+	var routes []Route[int]
+	m.Walk(func(route Route[int]) {
+		routes = append(routes, route)
+	})
+	want := []Route[int]{
+		{Path: "/users/:id", Value: 1, Meta: Meta{"scopes": []string{"users:read"}, "deprecated": true}},
+		{Path: "/health", Value: 2},
+	}
+	if !reflect.DeepEqual(routes, want) {
+		t.Errorf("wrong routes walked: expected %+v, got %+v", want, routes)
+	}
+}
@@ -0,0 +1,104 @@
+package pathmatcher
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrControlByte is returned by Normalizer.Normalize for paths containing NUL
+// or other control bytes when RejectControl is set.
+var ErrControlByte = errors.New("pathmatcher: path contains a control byte")
+
+// Normalizer rewrites request paths into a canonical form before lookup. The
+// zero value leaves paths unchanged; each field enables one step, applied in
+// the order they are listed.
+//
+// Like CleanPath, Normalize does not allocate if the path is already in
+// canonical form.
+type Normalizer struct {
+	// RejectControl rejects paths containing NUL or other ASCII control
+	// bytes (0x00-0x1F and 0x7F) with ErrControlByte.
+	RejectControl bool
+
+	// Clean applies CleanPath.
+	Clean bool
+
+	// CollapseSlashes replaces runs of slashes with a single slash, leaving
+	// . and .. elements alone. It is redundant if Clean is set.
+	CollapseSlashes bool
+
+	// NFC applies Unicode normalization form C.
+	NFC bool
+
+	// Lowercase maps the path to lower case.
+	Lowercase bool
+
+	// RemoveTrailingSlash removes a trailing slash from any path but "/".
+	RemoveTrailingSlash bool
+}
+
+// Normalize returns the canonical form of p and whether it differs from p. A
+// router can redirect the client to the canonical path if it changed.
+func (n *Normalizer) Normalize(p string) (normalized string, changed bool, err error) {
+	normalized = p
+
+	if n.RejectControl {
+		for i := 0; i < len(p); i++ {
+			if c := p[i]; c < 0x20 || c == 0x7f {
+				return "", false, ErrControlByte
+			}
+		}
+	}
+
+	if n.Clean {
+		normalized = CleanPath(normalized)
+	} else if n.CollapseSlashes && strings.Contains(normalized, "//") {
+		normalized = collapseSlashes(normalized)
+	}
+
+	// QuickSpanString doesn't allocate, unlike IsNormalString
+	if n.NFC && norm.NFC.QuickSpanString(normalized) != len(normalized) {
+		normalized = norm.NFC.String(normalized)
+	}
+
+	if n.Lowercase && hasUpper(normalized) {
+		normalized = strings.ToLower(normalized)
+	}
+
+	if n.RemoveTrailingSlash && len(normalized) > 1 && normalized[len(normalized)-1] == '/' {
+		normalized = strings.TrimRight(normalized, "/")
+		if normalized == "" {
+			normalized = "/"
+		}
+	}
+
+	return normalized, normalized != p, nil
+}
+
+func collapseSlashes(p string) string {
+	buf := make([]byte, 0, len(p))
+	for i := 0; i < len(p); i++ {
+		if p[i] == '/' && i > 0 && p[i-1] == '/' {
+			continue
+		}
+		buf = append(buf, p[i])
+	}
+	return string(buf)
+}
+
+// hasUpper reports whether strings.ToLower would change s.
+func hasUpper(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			return strings.ToLower(s[i:]) != s[i:]
+		}
+		if 'A' <= c && c <= 'Z' {
+			return true
+		}
+	}
+	return false
+}
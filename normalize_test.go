@@ -0,0 +1,77 @@
+package pathmatcher
+
+import (
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		n       Normalizer
+		in, out string
+		err     error
+	}{
+		{Normalizer{}, "//a/./B/", "//a/./B/", nil},
+		{Normalizer{Clean: true}, "//a/./b/../c/", "/a/c/", nil},
+		{Normalizer{CollapseSlashes: true}, "//a///./b//", "/a/./b/", nil},
+		{Normalizer{Lowercase: true}, "/Hello/ÄPFEL", "/hello/äpfel", nil},
+		{Normalizer{NFC: true}, "/café", "/café", nil},
+		{Normalizer{RemoveTrailingSlash: true}, "/a/b//", "/a/b", nil},
+		{Normalizer{RemoveTrailingSlash: true}, "/", "/", nil},
+		{Normalizer{RemoveTrailingSlash: true}, "//", "/", nil},
+		{Normalizer{RejectControl: true}, "/a\x00b", "", ErrControlByte},
+		{Normalizer{RejectControl: true}, "/a\nb", "", ErrControlByte},
+		{Normalizer{RejectControl: true}, "/a\x7fb", "", ErrControlByte},
+		{Normalizer{RejectControl: true}, "/a b/ü", "/a b/ü", nil},
+		{Normalizer{Clean: true, Lowercase: true, RemoveTrailingSlash: true}, "/A//B/../C/", "/a/c", nil},
+	}
+	for _, test := range tests {
+		out, changed, err := test.n.Normalize(test.in)
+		if out != test.out || err != test.err || changed != (err == nil && out != test.in) {
+			t.Errorf("%+v.Normalize(%q) = %q, %t, %v; want %q, %v", test.n, test.in, out, changed, err, test.out, test.err)
+		}
+	}
+}
+
+func TestNormalizeMallocs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping malloc count in short mode")
+	}
+
+	n := Normalizer{
+		RejectControl:       true,
+		Clean:               true,
+		NFC:                 true,
+		Lowercase:           true,
+		RemoveTrailingSlash: true,
+	}
+	for _, path := range []string{"/", "/abc", "/a/b/c", "/äpfel/straße", "/a/b/"} {
+		allocs := testing.AllocsPerRun(100, func() { n.Normalize(path) })
+		if allocs > 0 {
+			t.Errorf("Normalize(%q): %v allocs, want zero", path, allocs)
+		}
+	}
+}
+
+func TestMatcherNormalizer(t *testing.T) {
+	m := NewHttpMatcher[int]()
+	m.GET("/users/:id", 1)
+	m.Normalizer = &Normalizer{RejectControl: true, Clean: true, Lowercase: true}
+
+	tests := []struct {
+		path  string
+		value int
+		redir bool
+	}{
+		{"/users/42", 1, false},
+		{"/Users/42", 0, true},
+		{"//users/./42", 0, true},
+		{"/Other/42", 0, false},
+		{"/users/4\x002", 0, false},
+	}
+	for _, test := range tests {
+		_, value, _, redir := m.Find("GET", test.path)
+		if value != test.value || redir != test.redir {
+			t.Errorf("Find(%q) = %d, %t; want %d, %t", test.path, value, redir, test.value, test.redir)
+		}
+	}
+}
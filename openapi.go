@@ -0,0 +1,319 @@
+package pathmatcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// OpenAPIDocument is the subset of an OpenAPI 3 document needed to describe
+// routes: the paths and the operations on them. Only JSON documents are
+// supported.
+type OpenAPIDocument struct {
+	OpenAPI string                                  `json:"openapi"`
+	Info    OpenAPIInfo                             `json:"info"`
+	Paths   map[string]map[string]*OpenAPIOperation `json:"paths"`
+}
+
+// OpenAPIInfo is the info object of an OpenAPI document.
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenAPIOperation is an operation object of an OpenAPI document, keyed by the
+// lower case method in its path item.
+type OpenAPIOperation struct {
+	OperationID string                     `json:"operationId,omitempty"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses,omitempty"`
+}
+
+// OpenAPIParameter is a parameter object of an OpenAPI operation.
+type OpenAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required,omitempty"`
+	Schema   map[string]any `json:"schema,omitempty"`
+}
+
+// OpenAPIResponse is a response object of an OpenAPI operation.
+type OpenAPIResponse struct {
+	Description string `json:"description"`
+}
+
+// OpenAPIOptions configures the translation of OpenAPI path templates to
+// patterns.
+type OpenAPIOptions struct {
+	// CatchAll accepts the "{name+}" extension for catch-all params, see
+	// OpenAPIPathToPattern. It is not part of OpenAPI 3, so templates using it
+	// are rejected unless it is set.
+	CatchAll bool
+}
+
+var openAPIMethods = [...]string{
+	http.MethodGet,
+	http.MethodPut,
+	http.MethodPost,
+	http.MethodDelete,
+	http.MethodOptions,
+	http.MethodHead,
+	http.MethodPatch,
+	http.MethodTrace,
+}
+
+// ReadOpenAPI reads the paths and operations of an OpenAPI 3 document in JSON.
+// Path item fields other than operations, such as shared parameters or
+// summaries, are ignored.
+func ReadOpenAPI(r io.Reader) (*OpenAPIDocument, error) {
+	var raw struct {
+		OpenAPI string                                `json:"openapi"`
+		Info    OpenAPIInfo                           `json:"info"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("pathmatcher: reading OpenAPI document: %w", err)
+	}
+
+	doc := &OpenAPIDocument{
+		OpenAPI: raw.OpenAPI,
+		Info:    raw.Info,
+		Paths:   make(map[string]map[string]*OpenAPIOperation, len(raw.Paths)),
+	}
+	for template, item := range raw.Paths {
+		ops := make(map[string]*OpenAPIOperation)
+		for _, method := range openAPIMethods {
+			key := strings.ToLower(method)
+			data, ok := item[key]
+			if !ok {
+				continue
+			}
+			var op OpenAPIOperation
+			if err := json.Unmarshal(data, &op); err != nil {
+				return nil, fmt.Errorf("pathmatcher: reading OpenAPI operation %s %s: %w", method, template, err)
+			}
+			ops[key] = &op
+		}
+		doc.Paths[template] = ops
+	}
+	return doc, nil
+}
+
+// LoadOpenAPI builds an HttpMatcher from the operations of an OpenAPI 3
+// document in JSON. Path templates are translated with OpenAPIPathToPattern
+// and opts. The value function maps each operation, usually by its
+// OperationID, to the value to register for the method and pattern. The
+// operation ID and tags are attached to the route as metadata.
+func LoadOpenAPI[V any](r io.Reader, opts OpenAPIOptions, value func(method, pattern string, op *OpenAPIOperation) (V, error)) (*HttpMatcher[V], error) {
+	doc, err := ReadOpenAPI(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Register in a stable order so that errors are reproducible.
+	templates := make([]string, 0, len(doc.Paths))
+	for template := range doc.Paths {
+		templates = append(templates, template)
+	}
+	slices.Sort(templates)
+
+	m := NewHttpMatcher[V]()
+	for _, template := range templates {
+		pattern, err := OpenAPIPathToPattern(template, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, method := range openAPIMethods {
+			op, ok := doc.Paths[template][strings.ToLower(method)]
+			if !ok {
+				continue
+			}
+			v, err := value(method, pattern, op)
+			if err != nil {
+				return nil, fmt.Errorf("pathmatcher: operation %s %s: %w", method, template, err)
+			}
+			meta := Meta{"operationId": op.OperationID}
+			if len(op.Tags) > 0 {
+				meta["tags"] = op.Tags
+			}
+			if err := catchAdd(func() { m.AddMeta(method, pattern, v, meta) }); err != nil {
+				return nil, fmt.Errorf("pathmatcher: operation %s %s: %w", method, template, err)
+			}
+		}
+	}
+	return m, nil
+}
+
+// ExportOpenAPI describes the routes of the matcher as an OpenAPI 3 paths
+// skeleton. Patterns are translated with PatternToOpenAPIPath and every path
+// parameter is declared as a required string. The operationID function names
+// each operation; tags are taken from a "tags" entry of the route's metadata.
+//
+// The qualified values of an endpoint, see HttpMatcher.AddQualified, share its
+// operation, which is named after the first route Walk reports for it. Their
+// query parameters and headers are declared as string parameters, required if
+// every route of the endpoint requires them.
+func (m *HttpMatcher[V]) ExportOpenAPI(info OpenAPIInfo, operationID func(route Route[V]) string) *OpenAPIDocument {
+	doc := &OpenAPIDocument{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Paths:   make(map[string]map[string]*OpenAPIOperation),
+	}
+	// The routes of each operation, and how many of them require a query
+	// parameter or header, by location and name
+	routes := make(map[*OpenAPIOperation]int)
+	requiredBy := make(map[*OpenAPIOperation]map[[2]string]int)
+	m.Walk(func(route Route[V]) {
+		template := PatternToOpenAPIPath(route.Path)
+		method := strings.ToLower(route.Method)
+		op := doc.Paths[template][method]
+		if op == nil {
+			op = &OpenAPIOperation{
+				OperationID: operationID(route),
+				Responses:   map[string]OpenAPIResponse{"default": {Description: "default response"}},
+			}
+			op.Tags = metaTags(route.Meta["tags"])
+			for path := route.Path; ; {
+				wildcard, i, _ := findWildcard(path)
+				if i < 0 {
+					break
+				}
+				op.Parameters = append(op.Parameters, OpenAPIParameter{
+					Name:     wildcard[1:],
+					In:       "path",
+					Required: true,
+					Schema:   map[string]any{"type": "string"},
+				})
+				path = path[i+len(wildcard):]
+			}
+			if doc.Paths[template] == nil {
+				doc.Paths[template] = make(map[string]*OpenAPIOperation)
+			}
+			doc.Paths[template][method] = op
+			requiredBy[op] = make(map[[2]string]int)
+		}
+
+		routes[op]++
+		require := func(in string, names []string) {
+			slices.Sort(names)
+			for _, name := range names {
+				key := [2]string{in, name}
+				if requiredBy[op][key] == 0 {
+					op.Parameters = append(op.Parameters, OpenAPIParameter{
+						Name:   name,
+						In:     in,
+						Schema: map[string]any{"type": "string"},
+					})
+				}
+				requiredBy[op][key]++
+			}
+		}
+		require("query", maps.Keys(route.Query))
+		require("header", maps.Keys(route.Header))
+	})
+
+	for op, n := range routes {
+		for i := range op.Parameters {
+			if p := &op.Parameters[i]; p.In != "path" && requiredBy[op][[2]string{p.In, p.Name}] == n {
+				p.Required = true
+			}
+		}
+	}
+	return doc
+}
+
+// metaTags returns the tags stored in metadata, either as set by LoadOpenAPI
+// or as decoded from JSON by ReadRouteSpecs.
+func metaTags(v any) []string {
+	switch v := v.(type) {
+	case []string:
+		return v
+	case []any:
+		tags := make([]string, 0, len(v))
+		for _, tag := range v {
+			if tag, ok := tag.(string); ok {
+				tags = append(tags, tag)
+			}
+		}
+		return tags
+	}
+	return nil
+}
+
+// OpenAPIPathToPattern translates an OpenAPI path template to a pattern:
+// "{name}" becomes the param ":name". Templates that cannot be expressed as a
+// pattern, such as a param followed by more text in the same segment, are
+// reported as errors.
+//
+// OpenAPI 3 has no syntax for a param spanning several segments. As an
+// extension, if opts.CatchAll is set, a trailing "{name+}" becomes the
+// catch-all "*name"; PatternToOpenAPIPath writes catch-alls this way. Note that
+// the value of a catch-all includes the leading slash.
+func OpenAPIPathToPattern(template string, opts OpenAPIOptions) (string, error) {
+	var sb strings.Builder
+	for rest := template; rest != ""; {
+		start := strings.IndexAny(rest, "{}:*")
+		if start < 0 {
+			sb.WriteString(rest)
+			break
+		}
+		if rest[start] != '{' {
+			return "", fmt.Errorf("pathmatcher: unexpected '%c' in OpenAPI path '%s'", rest[start], template)
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("pathmatcher: unterminated parameter in OpenAPI path '%s'", template)
+		}
+		end += start
+		name := rest[start+1 : end]
+		sb.WriteString(rest[:start])
+		rest = rest[end+1:]
+
+		if rest != "" && rest[0] != '/' {
+			return "", fmt.Errorf("pathmatcher: parameter '%s' must end its segment in OpenAPI path '%s'", name, template)
+		}
+		if strings.HasSuffix(name, "+") {
+			if !opts.CatchAll {
+				return "", fmt.Errorf("pathmatcher: catch-all parameter '%s' is an extension to OpenAPI 3 that is not enabled in OpenAPI path '%s'", name, template)
+			}
+			if rest != "" || !strings.HasSuffix(sb.String(), "/") {
+				return "", fmt.Errorf("pathmatcher: catch-all parameter '%s' must be the last segment in OpenAPI path '%s'", name, template)
+			}
+			sb.WriteByte('*')
+			sb.WriteString(name[:len(name)-1])
+		} else {
+			sb.WriteByte(':')
+			sb.WriteString(name)
+		}
+	}
+	return sb.String(), nil
+}
+
+// PatternToOpenAPIPath translates a pattern to an OpenAPI path template, the
+// inverse of OpenAPIPathToPattern. Catch-alls are written with the "{name+}"
+// extension.
+func PatternToOpenAPIPath(pattern string) string {
+	var sb strings.Builder
+	for path := pattern; ; {
+		wildcard, i, _ := findWildcard(path)
+		if i < 0 {
+			sb.WriteString(path)
+			break
+		}
+		sb.WriteString(path[:i])
+		sb.WriteByte('{')
+		sb.WriteString(wildcard[1:])
+		if wildcard[0] == '*' {
+			sb.WriteByte('+')
+		}
+		sb.WriteByte('}')
+		path = path[i+len(wildcard):]
+	}
+	return sb.String()
+}
@@ -0,0 +1,185 @@
+package pathmatcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPIPathTranslation(t *testing.T) {
+	tests := []struct {
+		template, pattern string
+	}{
+		{"/", "/"},
+		{"/pets", "/pets"},
+		{"/pets/{petId}", "/pets/:petId"},
+		{"/pets/{petId}/toys/{toyId}/", "/pets/:petId/toys/:toyId/"},
+		{"/user_{name}", "/user_:name"},
+		{"/static/{path+}", "/static/*path"},
+	}
+	for _, test := range tests {
+		pattern, err := OpenAPIPathToPattern(test.template, OpenAPIOptions{CatchAll: true})
+		if err != nil || pattern != test.pattern {
+			t.Errorf("OpenAPIPathToPattern(%q) = %q, %v; want %q", test.template, pattern, err, test.pattern)
+		}
+		if template := PatternToOpenAPIPath(test.pattern); template != test.template {
+			t.Errorf("PatternToOpenAPIPath(%q) = %q; want %q", test.pattern, template, test.template)
+		}
+	}
+
+	invalid := []string{
+		"/pets/{petId",
+		"/pets/{petId}.json",
+		"/files/{path+}/raw",
+		"/files{path+}",
+		"/pets/:petId",
+		"/pets/*",
+	}
+	for _, template := range invalid {
+		if pattern, err := OpenAPIPathToPattern(template, OpenAPIOptions{CatchAll: true}); err == nil {
+			t.Errorf("expected error for %q, got %q", template, pattern)
+		}
+	}
+	if pattern, err := OpenAPIPathToPattern("/static/{path+}", OpenAPIOptions{}); err == nil {
+		t.Errorf("expected error for catch-all extension, got %q", pattern)
+	}
+}
+
+func TestLoadOpenAPI(t *testing.T) {
+	f, err := os.Open("testdata/petstore.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	value := func(method, pattern string, op *OpenAPIOperation) (string, error) {
+		return op.OperationID, nil
+	}
+	if _, err := LoadOpenAPI(f, OpenAPIOptions{}, value); err == nil || !strings.Contains(err.Error(), "{path+}") {
+		t.Errorf("wrong error without the catch-all extension: %v", err)
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadOpenAPI(f, OpenAPIOptions{CatchAll: true}, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checks := []struct {
+		method, path, value string
+		params              Params
+	}{
+		{"GET", "/pets", "listPets", nil},
+		{"POST", "/pets", "createPets", nil},
+		{"GET", "/pets/7", "showPetById", Params{{"petId", "7"}}},
+		{"DELETE", "/pets/7", "deletePet", Params{{"petId", "7"}}},
+		{"GET", "/static/css/site.css", "static", Params{{"path", "/css/site.css"}}},
+		{"PUT", "/pets", "", nil},
+	}
+	for _, check := range checks {
+		_, value, params, _ := m.Find(check.method, check.path)
+		if value != check.value || !reflect.DeepEqual(params, check.params) {
+			t.Errorf("%s %s: got %q %+v, want %q %+v", check.method, check.path, value, params, check.value, check.params)
+		}
+	}
+
+	if meta := m.Meta("GET", "/pets/:petId"); meta["operationId"] != "showPetById" ||
+		!reflect.DeepEqual(meta["tags"], []string{"pets"}) {
+		t.Errorf("wrong meta: %+v", meta)
+	}
+
+	if _, err := f.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := ReadOpenAPI(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	limit := doc.Paths["/pets"]["get"].Parameters[0]
+	if limit.Name != "limit" || limit.Schema["type"] != "integer" || limit.Schema["maximum"] != 100.0 {
+		t.Errorf("wrong parameter: %+v", limit)
+	}
+}
+
+func TestExportOpenAPI(t *testing.T) {
+	m := NewHttpMatcher[string]()
+	m.AddMeta("GET", "/pets/:petId", "showPetById", Meta{"tags": []string{"pets"}})
+	m.GET("/files/*path", "files")
+
+	doc := m.ExportOpenAPI(OpenAPIInfo{Title: "Petstore", Version: "1"}, func(route Route[string]) string {
+		return route.Value
+	})
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(doc); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadOpenAPI(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, doc) {
+		t.Errorf("document changed in round trip:\n%+v\n%+v", read, doc)
+	}
+
+	op := doc.Paths["/pets/{petId}"]["get"]
+	if op == nil || op.OperationID != "showPetById" || !reflect.DeepEqual(op.Tags, []string{"pets"}) {
+		t.Fatalf("wrong operation: %+v", op)
+	}
+	wantParams := []OpenAPIParameter{{Name: "petId", In: "path", Required: true, Schema: map[string]any{"type": "string"}}}
+	if !reflect.DeepEqual(op.Parameters, wantParams) {
+		t.Errorf("wrong parameters: %+v", op.Parameters)
+	}
+	if op := doc.Paths["/files/{path+}"]["get"]; op == nil || op.Parameters[0].Name != "path" {
+		t.Errorf("wrong catch-all operation: %+v", op)
+	}
+}
+
+func TestExportOpenAPILoadedTags(t *testing.T) {
+	specs := `[{"method": "GET", "path": "/pets", "name": "listPets", "meta": {"tags": ["pets", "read"]}}]`
+	m, err := LoadHttpMatcher(strings.NewReader(specs), func(spec RouteSpec) (string, error) {
+		return spec.Name, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := m.ExportOpenAPI(OpenAPIInfo{}, func(route Route[string]) string { return route.Value })
+	if op := doc.Paths["/pets"]["get"]; op == nil || !reflect.DeepEqual(op.Tags, []string{"pets", "read"}) {
+		t.Errorf("wrong operation: %+v", op)
+	}
+}
+
+func TestExportOpenAPIQualified(t *testing.T) {
+	m := NewHttpMatcher[string]()
+	m.GET("/pets", "listPets")
+	m.AddQuery("GET", "/pets", url.Values{"tag": nil}, "listPetsByTag")
+	m.AddQualified("GET", "/pets", url.Values{"tag": nil}, http.Header{"Accept": {"text/csv"}}, "exportPets")
+	m.AddHeader("POST", "/pets", http.Header{"Content-Type": {"application/json"}}, "createPet")
+
+	doc := m.ExportOpenAPI(OpenAPIInfo{}, func(route Route[string]) string { return route.Value })
+	str := map[string]any{"type": "string"}
+	tests := []struct {
+		method, operationID string
+		params              []OpenAPIParameter
+	}{
+		{"get", "listPets", []OpenAPIParameter{
+			{Name: "tag", In: "query", Schema: str},
+			{Name: "Accept", In: "header", Schema: str},
+		}},
+		{"post", "createPet", []OpenAPIParameter{
+			{Name: "Content-Type", In: "header", Required: true, Schema: str},
+		}},
+	}
+	for _, test := range tests {
+		op := doc.Paths["/pets"][test.method]
+		if op == nil || op.OperationID != test.operationID || !reflect.DeepEqual(op.Parameters, test.params) {
+			t.Errorf("%s: wrong operation %+v", test.method, op)
+		}
+	}
+}
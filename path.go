@@ -148,3 +148,94 @@ func bufApp(buf *[]byte, s string, w int, c byte) {
 	}
 	b[w] = c
 }
+
+// AppendCleanPath appends the canonical form of p, as returned by CleanPath,
+// to dst and returns the extended buffer. It does not allocate if dst has room
+// for len(p)+1 more bytes, so callers can clean paths of any length into pooled
+// buffers.
+func AppendCleanPath(dst []byte, p string) []byte {
+	off := len(dst)
+	if p == "" || p[0] != '/' {
+		dst = append(dst, '/')
+	}
+	dst = append(dst, p...)
+	return dst[:off+len(cleanRooted(dst[off:]))]
+}
+
+// CleanPathBytes is like CleanPath, but cleans p in place and returns the
+// shortened slice. If p does not begin with '/', the slash is inserted in
+// front, which only allocates if p has no spare capacity.
+func CleanPathBytes(p []byte) []byte {
+	if len(p) == 0 || p[0] != '/' {
+		p = append(p, 0)
+		copy(p[1:], p)
+		p[0] = '/'
+	}
+	return cleanRooted(p)
+}
+
+// cleanRooted applies the rules of CleanPath to p in place. p must begin with
+// '/'. The cleaned path is never longer than p and every byte is written at or
+// before the position it is read from, so no separate buffer is needed.
+func cleanRooted(p []byte) []byte {
+	n := len(p)
+
+	// Invariants:
+	//      reading from p; r is index of next byte to process.
+	//      writing to p; w is index of next byte to write, w <= r.
+	r := 1
+	w := 1
+
+	trailing := n > 1 && p[n-1] == '/'
+
+	for r < n {
+		switch {
+		case p[r] == '/':
+			// empty path element, trailing slash is added after the end
+			r++
+
+		case p[r] == '.' && r+1 == n:
+			trailing = true
+			r++
+
+		case p[r] == '.' && p[r+1] == '/':
+			// . element
+			r += 2
+
+		case p[r] == '.' && p[r+1] == '.' && (r+2 == n || p[r+2] == '/'):
+			// .. element: remove to last /
+			r += 3
+
+			if w > 1 {
+				// can backtrack
+				w--
+				for w > 1 && p[w] != '/' {
+					w--
+				}
+			}
+
+		default:
+			// Real path element.
+			// Add slash if needed
+			if w > 1 {
+				p[w] = '/'
+				w++
+			}
+
+			// Copy element
+			for r < n && p[r] != '/' {
+				p[w] = p[r]
+				w++
+				r++
+			}
+		}
+	}
+
+	// Re-append trailing slash
+	if trailing && w > 1 {
+		p[w] = '/'
+		w++
+	}
+
+	return p[:w]
+}
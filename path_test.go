@@ -91,6 +91,64 @@ func TestPathCleanMallocs(t *testing.T) {
 	}
 }
 
+func TestAppendCleanPath(t *testing.T) {
+	tests := append(cleanTests, genLongPaths()...)
+
+	prefix := []byte("prefix")
+	for _, test := range tests {
+		if s := string(AppendCleanPath(nil, test.path)); s != test.result {
+			t.Errorf("AppendCleanPath(nil, %q) = %q, want %q", test.path, s, test.result)
+		}
+		if s := string(AppendCleanPath(prefix[:len(prefix):len(prefix)], test.path)); s != "prefix"+test.result {
+			t.Errorf("AppendCleanPath(%q, %q) = %q, want %q", prefix, test.path, s, "prefix"+test.result)
+		}
+		if s := string(CleanPathBytes([]byte(test.path))); s != test.result {
+			t.Errorf("CleanPathBytes(%q) = %q, want %q", test.path, s, test.result)
+		}
+	}
+}
+
+func TestAppendCleanPathMallocs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping malloc count in short mode")
+	}
+
+	// Unlike CleanPath, neither long paths nor paths which need to be
+	// modified allocate when cleaned into a large enough buffer.
+	tests := append(cleanTests, genLongPaths()...)
+	buf := make([]byte, 0, 2048)
+	for _, test := range tests {
+		test := test
+		allocs := testing.AllocsPerRun(10, func() { AppendCleanPath(buf[:0], test.path) })
+		if allocs > 0 {
+			t.Errorf("AppendCleanPath(buf, %q): %v allocs, want zero", test.path, allocs)
+		}
+
+		allocs = testing.AllocsPerRun(10, func() {
+			b := append(buf[:0], test.path...)
+			CleanPathBytes(b)
+		})
+		if allocs > 0 {
+			t.Errorf("CleanPathBytes(%q): %v allocs, want zero", test.path, allocs)
+		}
+	}
+}
+
+func TestPathCleanLongMallocs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping malloc count in short mode")
+	}
+
+	// Already clean paths are returned as is, whatever their length.
+	for _, test := range genLongPaths() {
+		test := test
+		allocs := testing.AllocsPerRun(10, func() { CleanPath(test.result) })
+		if allocs > 0 {
+			t.Errorf("CleanPath(%q): %v allocs, want zero", test.result, allocs)
+		}
+	}
+}
+
 func BenchmarkPathClean(b *testing.B) {
 	b.ReportAllocs()
 
@@ -147,3 +205,16 @@ func BenchmarkPathCleanLong(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkAppendCleanPathLong(b *testing.B) {
+	cleanTests := genLongPaths()
+	buf := make([]byte, 0, 2048)
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		for _, test := range cleanTests {
+			buf = AppendCleanPath(buf[:0], test.path)
+		}
+	}
+}
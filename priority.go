@@ -0,0 +1,260 @@
+package pathmatcher
+
+import (
+	"sort"
+	"strings"
+)
+
+// PriorityMatcher associates parametrized paths with values like Matcher,
+// but allows patterns to overlap. A path matching several patterns is matched
+// by the most specific one. Patterns are compared segment by segment, from
+// left to right, and in each segment:
+//
+//  1. a longer literal prefix wins, so "/users/new" wins over "/users/n:x",
+//     which wins over "/users/:id";
+//  2. then a static segment wins over a constrained param, like ":id|int",
+//     which wins over a param, like ":id", which wins over a catch-all, like
+//     "*path";
+//  3. then params with different constraints or names are tried in the order
+//     they were added.
+//
+// As a result, the pattern with the longer literal prefix wins: "/a/:x/c"
+// wins over "/:y/b/c" for the path "/a/b/c". FindAll lists every pattern a
+// path matches in this order.
+//
+// Params and catch-alls work like for Matcher, except that params never
+// match an empty value. Only patterns that match exactly the same paths, like
+// "/users/:id" and "/users/:name", still conflict.
+//
+// The matcher walks every candidate segment until it finds a match, so
+// overlapping patterns cost backtracking that Matcher avoids.
+type PriorityMatcher[V any] struct {
+	root   pnode[V]
+	shapes map[string]string // patterns by their wildcards with names removed
+
+	// Constraints are the param constraints patterns may refer to, by name.
+	// A param ":id|int" only matches a value if Constraints["int"] reports
+	// true for it. The value of a constrained catch-all includes the leading
+	// slash, as in its params.
+	Constraints map[string]func(value string) bool
+}
+
+// pnode is the node of a PriorityMatcher for a path prefix ending with a
+// slash. Its children match the next segment.
+type pnode[V any] struct {
+	static    map[string]*pnode[V]
+	wildcards []*pwildcard[V] // in priority order
+	value     *V
+	pattern   string
+}
+
+// pwildcard is a param or catch-all child of a pnode. The segment it matches
+// starts with prefix.
+type pwildcard[V any] struct {
+	prefix     string
+	name       string
+	constraint string
+	catchAll   bool
+	node       pnode[V]
+}
+
+// rank orders wildcards with the same prefix in a segment.
+func (w *pwildcard[V]) rank() int {
+	rank := 0
+	if w.catchAll {
+		rank = 2
+	}
+	if w.constraint == "" {
+		rank++
+	}
+	return rank
+}
+
+func NewPriorityMatcher[V any]() *PriorityMatcher[V] {
+	return &PriorityMatcher[V]{}
+}
+
+// Add registers value for path. It panics if path is invalid, refers to an
+// unknown constraint, or matches exactly the same paths as a pattern added
+// before.
+func (m *PriorityMatcher[V]) Add(path string, value V) {
+	if len(path) < 1 || path[0] != '/' {
+		panic("path must begin with '/' in path '" + path + "'")
+	}
+
+	// Patterns differing only in param names match the same paths
+	var shape strings.Builder
+	n := &m.root
+	for rest := path[1:]; ; {
+		seg, next, more := strings.Cut(rest, "/")
+		i := strings.IndexAny(seg, ":*")
+		shape.WriteByte('/')
+		if i < 0 {
+			shape.WriteString(seg)
+			child := n.static[seg]
+			if child == nil {
+				if n.static == nil {
+					n.static = make(map[string]*pnode[V])
+				}
+				child = &pnode[V]{}
+				n.static[seg] = child
+			}
+			n = child
+		} else {
+			var w *pwildcard[V]
+			n, w = m.addWildcard(n, path, seg, i, more)
+			shape.WriteString(seg[:i+1] + "|" + w.constraint)
+		}
+		if !more {
+			break
+		}
+		rest = next
+	}
+
+	if other, ok := m.shapes[shape.String()]; ok {
+		if other == path {
+			panic("a handle is already registered for path '" + path + "'")
+		}
+		panic("path '" + path + "' matches the same paths as existing path '" + other + "'")
+	}
+	if m.shapes == nil {
+		m.shapes = make(map[string]string)
+	}
+	m.shapes[shape.String()] = path
+	n.value = &value
+	n.pattern = path
+}
+
+// addWildcard returns the wildcard child of n for the segment seg of path,
+// whose wildcard starts at i, and its node, adding it if needed. Params with
+// different names get different children, tried in the order they were added.
+func (m *PriorityMatcher[V]) addWildcard(n *pnode[V], path, seg string, i int, more bool) (*pnode[V], *pwildcard[V]) {
+	w := &pwildcard[V]{
+		prefix:   seg[:i],
+		name:     seg[i+1:],
+		catchAll: seg[i] == '*',
+	}
+	if strings.ContainsAny(w.name, ":*") {
+		panic("only one wildcard per path segment is allowed, has: '" +
+			seg + "' in path '" + path + "'")
+	}
+	w.name, w.constraint, _ = strings.Cut(w.name, "|")
+	if w.name == "" {
+		panic("wildcards must be named with a non-empty name in path '" + path + "'")
+	}
+	if w.constraint != "" && m.Constraints[w.constraint] == nil {
+		panic("unknown constraint '" + w.constraint + "' in path '" + path + "'")
+	}
+	if w.catchAll {
+		if more {
+			panic("catch-all routes are only allowed at the end of the path in path '" + path + "'")
+		}
+		if w.prefix != "" {
+			panic("no / before catch-all in path '" + path + "'")
+		}
+	}
+
+	for _, other := range n.wildcards {
+		if other.prefix == w.prefix && other.catchAll == w.catchAll &&
+			other.constraint == w.constraint && other.name == w.name {
+			return &other.node, other
+		}
+	}
+
+	n.wildcards = append(n.wildcards, w)
+	sort.SliceStable(n.wildcards, func(a, b int) bool {
+		wa, wb := n.wildcards[a], n.wildcards[b]
+		if len(wa.prefix) != len(wb.prefix) {
+			return len(wa.prefix) > len(wb.prefix)
+		}
+		return wa.rank() < wb.rank()
+	})
+	return &w.node, w
+}
+
+// find calls yield with every node below n holding a value for path, which is
+// the rest of the path after a slash, in priority order, and the params that
+// lead to it. It stops and returns false when yield does.
+func (m *PriorityMatcher[V]) find(n *pnode[V], path string, ps Params, yield func(*pnode[V], Params) bool) bool {
+	seg, rest, more := strings.Cut(path, "/")
+	if child := n.static[seg]; child != nil {
+		if more {
+			if !m.find(child, rest, ps, yield) {
+				return false
+			}
+		} else if child.value != nil && !yield(child, ps) {
+			return false
+		}
+	}
+
+	for _, w := range n.wildcards {
+		if !strings.HasPrefix(seg, w.prefix) {
+			continue
+		}
+		value := seg[len(w.prefix):]
+		if w.catchAll {
+			value = "/" + path
+		}
+		if value == "" || (w.constraint != "" && !m.Constraints[w.constraint](value)) {
+			continue
+		}
+		ps := append(ps, Param{Key: w.name, Value: value})
+		if more && !w.catchAll {
+			if !m.find(&w.node, rest, ps, yield) {
+				return false
+			}
+		} else if w.node.value != nil && !yield(&w.node, ps) {
+			return false
+		}
+	}
+	return true
+}
+
+// Find returns the value of the most specific pattern matching path, see
+// PriorityMatcher. If no pattern matches, redir recommends a redirect to the
+// path with the trailing slash added or removed if that one matches.
+func (m *PriorityMatcher[V]) Find(path string) (match string, value V, params Params, redir bool) {
+	if path == "" || path[0] != '/' {
+		return
+	}
+	var found *pnode[V]
+	m.find(&m.root, path[1:], nil, func(n *pnode[V], ps Params) bool {
+		found, params = n, ps
+		return false
+	})
+	if found == nil {
+		params = nil
+		if path != "/" {
+			if strings.HasSuffix(path, "/") {
+				path = path[:len(path)-1]
+			} else {
+				path += "/"
+			}
+			m.find(&m.root, path[1:], nil, func(*pnode[V], Params) bool {
+				redir = true
+				return false
+			})
+		}
+		return
+	}
+	return found.pattern, *found.value, params, false
+}
+
+// FindAll returns every pattern matching path in priority order, with the
+// params it matches with. The first one is the one Find returns, the others
+// are shadowed by it.
+func (m *PriorityMatcher[V]) FindAll(path string) []Candidate[V] {
+	if path == "" || path[0] != '/' {
+		return nil
+	}
+	var candidates []Candidate[V]
+	m.find(&m.root, path[1:], nil, func(n *pnode[V], ps Params) bool {
+		candidates = append(candidates, Candidate[V]{
+			Pattern: n.pattern,
+			Value:   *n.value,
+			Params:  append(Params(nil), ps...),
+		})
+		return true
+	})
+	return candidates
+}
@@ -0,0 +1,160 @@
+package pathmatcher
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func newTestPriorityMatcher(patterns ...string) *PriorityMatcher[string] {
+	m := NewPriorityMatcher[string]()
+	m.Constraints = map[string]func(string) bool{
+		"int": func(v string) bool {
+			_, err := strconv.Atoi(v)
+			return err == nil
+		},
+		"hex": func(v string) bool {
+			_, err := strconv.ParseUint(v, 16, 64)
+			return err == nil
+		},
+	}
+	for _, pattern := range patterns {
+		m.Add(pattern, pattern)
+	}
+	return m
+}
+
+func TestPriorityMatcher(t *testing.T) {
+	m := newTestPriorityMatcher(
+		"/",
+		"/users/new",
+		"/users/n:x",
+		"/users/:id|int",
+		"/users/:id|hex",
+		"/users/:name",
+		"/users/*path",
+		"/users/:name/posts",
+		"/users/:uid/comments",
+		"/a/:x/c",
+		"/:y/b/c",
+		"/files/*path",
+		"/files/:name|int/raw",
+	)
+
+	tests := []struct {
+		path   string
+		match  string
+		params Params
+		redir  bool
+	}{
+		{"/", "/", nil, false},
+		{"/users/new", "/users/new", nil, false},
+		{"/users/nancy", "/users/n:x", Params{{"x", "ancy"}}, false},
+		{"/users/42", "/users/:id|int", Params{{"id", "42"}}, false},
+		{"/users/ff", "/users/:id|hex", Params{{"id", "ff"}}, false},
+		{"/users/gopher", "/users/:name", Params{{"name", "gopher"}}, false},
+		{"/users/gopher/posts", "/users/:name/posts", Params{{"name", "gopher"}}, false},
+		{"/users/gopher/comments", "/users/:uid/comments", Params{{"uid", "gopher"}}, false},
+		{"/users/gopher/likes", "/users/*path", Params{{"path", "/gopher/likes"}}, false},
+		{"/users/", "/users/*path", Params{{"path", "/"}}, false},
+		{"/a/b/c", "/a/:x/c", Params{{"x", "b"}}, false},
+		{"/z/b/c", "/:y/b/c", Params{{"y", "z"}}, false},
+		{"/files/1/raw", "/files/:name|int/raw", Params{{"name", "1"}}, false},
+		{"/files/x/raw", "/files/*path", Params{{"path", "/x/raw"}}, false},
+		{"/users", "", nil, true},
+		{"/a/b/c/", "", nil, true},
+		{"/a/b/d", "", nil, false},
+		{"", "", nil, false},
+	}
+	for _, test := range tests {
+		match, value, params, redir := m.Find(test.path)
+		if match != test.match || value != test.match || !reflect.DeepEqual(params, test.params) || redir != test.redir {
+			t.Errorf("Find(%q) = %q, %q, %v, %t; want %q, %v, %t",
+				test.path, match, value, params, redir, test.match, test.params, test.redir)
+		}
+		if candidates := m.FindAll(test.path); test.match != "" && (len(candidates) == 0 || candidates[0].Pattern != match) {
+			t.Errorf("FindAll(%q) = %v, want %q first", test.path, candidates, test.match)
+		}
+	}
+}
+
+func TestPriorityMatcherFindAll(t *testing.T) {
+	m := newTestPriorityMatcher(
+		"/users/:name",
+		"/users/:id|int",
+		"/users/*path",
+		"/:section/42",
+		"/users/42",
+	)
+	want := []Candidate[string]{
+		{Pattern: "/users/42", Value: "/users/42"},
+		{Pattern: "/users/:id|int", Value: "/users/:id|int", Params: Params{{"id", "42"}}},
+		{Pattern: "/users/:name", Value: "/users/:name", Params: Params{{"name", "42"}}},
+		{Pattern: "/users/*path", Value: "/users/*path", Params: Params{{"path", "/42"}}},
+		{Pattern: "/:section/42", Value: "/:section/42", Params: Params{{"section", "users"}}},
+	}
+	if got := m.FindAll("/users/42"); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindAll = %v, want %v", got, want)
+	}
+	if got := m.FindAll("/nothing"); got != nil {
+		t.Errorf("FindAll = %v, want none", got)
+	}
+}
+
+func TestPriorityMatcherConflicts(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		conflict bool
+	}{
+		{[]string{"/users/:id", "/users/:name"}, true},
+		{[]string{"/users/:id|int", "/users/:n|int"}, true},
+		{[]string{"/users/*a", "/users/*b"}, true},
+		{[]string{"/users/:id", "/users/:id"}, true},
+		{[]string{"/users/:id", "/users/:id/posts"}, false},
+		{[]string{"/users/:id/posts", "/users/:uid/comments"}, false},
+		{[]string{"/users/:id/posts", "/users/:uid/posts"}, true},
+		{[]string{"/users/:id/*path", "/users/:uid/*rest"}, true},
+		{[]string{"/users/:id", "/users/:id|int", "/users/*path", "/users/x"}, false},
+		{[]string{"/users/:id|int", "/users/:id|hex"}, false},
+		{[]string{"/users/:id|float"}, true},
+		{[]string{"/users/*path/x"}, true},
+		{[]string{"/users/x*path"}, true},
+		{[]string{"/users/:a:b"}, true},
+		{[]string{"/users/:"}, true},
+		{[]string{"users"}, true},
+	}
+	for _, test := range tests {
+		recv := catchPanic(func() {
+			newTestPriorityMatcher(test.patterns...)
+		})
+		if conflict := recv != nil; conflict != test.conflict {
+			t.Errorf("%q: conflict %t, want %t (%v)", test.patterns, conflict, test.conflict, recv)
+		}
+	}
+}
+
+// TestPriorityMatcherTree checks that patterns a Matcher accepts are matched
+// the same way.
+func TestPriorityMatcherTree(t *testing.T) {
+	m := NewMatcher[string]()
+	pm := NewPriorityMatcher[string]()
+	for _, route := range freezeRoutes {
+		m.Add(route, route)
+		pm.Add(route, route)
+	}
+	for _, path := range freezePaths {
+		match, _, params, _ := m.Find(path)
+		if len(params) == 0 {
+			params = nil
+		}
+		for _, p := range params {
+			if p.Value == "" {
+				// Params never match an empty value
+				match, params = "", nil
+			}
+		}
+		if pmatch, _, pparams, _ := pm.Find(path); pmatch != match || !reflect.DeepEqual(pparams, params) {
+			t.Errorf("Find(%q) = %q, %v; Matcher %q, %v", path, pmatch, pparams, match, params)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package pathmatcher
+
+import (
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"sort"
+)
+
+// qualified holds the qualified values of a single endpoint. The tree stores
+// slot for the endpoint, which holds the unqualified fallback value if one was
+// added, or is a placeholder otherwise.
+type qualified[V any] struct {
+	slot     *V
+	fallback bool
+	routes   []*qualifiedRoute[V] // most specific first
+}
+
+type qualifiedRoute[V any] struct {
+	query  url.Values
+	header http.Header
+	keys   []string // sorted keys of query
+	conds  int      // number of required query keys and headers
+	pairs  int      // number of required values
+	value  V
+}
+
+// AddQuery adds an endpoint like Add, but qualified by required query
+// parameters. For every key in query, the request must carry the key; for
+// every value listed under a key, the request must carry that key=value pair.
+// For example, url.Values{"action": {"list"}, "verbose": nil} requires
+// "action=list" and any value for "verbose".
+//
+// Qualified values are selected by FindQuery after the path has matched. The
+// most specific qualified value satisfied by the query wins: the one with most
+// requirements, then the one with most key=value pairs, then the one added
+// first. If none is satisfied, the value added for the path with Add is the
+// fallback.
+func (m *HttpMatcher[V]) AddQuery(method, path string, query url.Values, value V) {
+	if len(query) == 0 {
+		panic("query must not be empty for path '" + path + "'")
+	}
+	m.AddQualified(method, path, query, nil, value)
+}
+
+// AddQualified adds an endpoint qualified by both required query parameters,
+// see AddQuery, and required headers, see AddHeader. Qualified values with
+// more requirements in total are more specific.
+func (m *HttpMatcher[V]) AddQualified(method, path string, query url.Values, header http.Header, value V) {
+	if !methodValid(method) {
+		panic(fmt.Sprintf("invalid method '%s'", method))
+	}
+	if len(query) == 0 && len(header) == 0 {
+		panic("query or header must not be empty for path '" + path + "'")
+	}
+
+	route := &qualifiedRoute[V]{query: query, value: value}
+	for key, values := range query {
+		route.keys = append(route.keys, key)
+		route.pairs += len(values)
+	}
+	sort.Strings(route.keys)
+	if len(header) > 0 {
+		route.header = make(http.Header, len(header))
+		for key, values := range header {
+			route.header[textproto.CanonicalMIMEHeaderKey(key)] = values
+			route.pairs += len(values)
+		}
+	}
+	route.conds = len(route.query) + len(route.header)
+
+	q := m.qualify(method, path)
+	for _, other := range q.routes {
+		if sameValues(other.query, route.query) && sameValues(other.header, route.header) {
+			panic("a handle is already registered for path '" + path +
+				"' with the same query and header requirements")
+		}
+	}
+
+	// Insert after every route at least as specific, keeping the order of
+	// registration among equals.
+	i := sort.Search(len(q.routes), func(i int) bool {
+		return q.routes[i].lessSpecific(route)
+	})
+	q.routes = append(q.routes, nil)
+	copy(q.routes[i+1:], q.routes[i:])
+	q.routes[i] = route
+
+	m.maxParams = max(m.maxParams, countParams(path)+uint(len(route.keys)))
+}
+
+func (r *qualifiedRoute[V]) lessSpecific(other *qualifiedRoute[V]) bool {
+	if r.conds != other.conds {
+		return r.conds < other.conds
+	}
+	return r.pairs < other.pairs
+}
+
+// qualify returns the qualified values of the endpoint, adding the endpoint
+// to the tree with a placeholder value if it doesn't exist yet.
+func (m *HttpMatcher[V]) qualify(method, path string) *qualified[V] {
+	if q := m.qualified[method][path]; q != nil {
+		return q
+	}
+	if m.qualified == nil {
+		m.qualified = make(map[string]map[string]*qualified[V])
+	}
+	if m.qualified[method] == nil {
+		m.qualified[method] = make(map[string]*qualified[V])
+	}
+
+	q := &qualified[V]{}
+	// A pattern matches itself, so the lookup finds an existing endpoint
+	// for exactly this path, if any.
+	if tree := m.trees[method]; tree != nil {
+		value, ps, match, _ := tree.findMatch(path, m.getParams)
+		m.putParams(ps)
+		if value != nil && match == path {
+			q.slot = value
+			q.fallback = true
+		}
+	}
+	if q.slot == nil {
+		q.slot = new(V)
+		m.add(method, path, q.slot)
+	}
+	m.qualified[method][path] = q
+	return q
+}
+
+// FindQuery is like Find, but selects between query-qualified values of the
+// matched endpoint, see AddQuery. The values of the query keys required by the
+// selected value are appended to params after the path params, ordered by key.
+func (m *HttpMatcher[V]) FindQuery(method, path string, query url.Values) (match string, value V, params Params, redir bool) {
+	match, value, params, redir, _ = m.lookup(m.Normalizer, method, path, query, nil)
+	m.Stats.record(method, match, redir)
+	return
+}
+
+// find returns the most specific route satisfied by query and header, or nil.
+// If no route is satisfied only because of their media types, err reports it.
+func (q *qualified[V]) find(query url.Values, header http.Header) (best *qualifiedRoute[V], err error) {
+	var bestQuality float64
+	for _, route := range q.routes {
+		if best != nil && route.lessSpecific(best) {
+			break
+		}
+		if !route.matches(query) || !route.matchesHeader(header) {
+			continue
+		}
+		if !route.matchesContentType(header) {
+			err = ErrUnsupportedMediaType
+			continue
+		}
+		quality := route.acceptQuality(header)
+		if quality == 0 {
+			if err == nil {
+				err = ErrNotAcceptable
+			}
+			continue
+		}
+		// Among equally specific routes, prefer the media type the
+		// client prefers.
+		if best == nil || quality > bestQuality {
+			best, bestQuality = route, quality
+		}
+	}
+	if best != nil {
+		err = nil
+	}
+	return best, err
+}
+
+func (r *qualifiedRoute[V]) matches(query url.Values) bool {
+	for key, want := range r.query {
+		have, ok := query[key]
+		if !ok {
+			return false
+		}
+		for _, w := range want {
+			if !contains(have, w) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// appendParams appends the values of the required query keys to ps.
+func (r *qualifiedRoute[V]) appendParams(ps *Params, query url.Values, params func() *Params) *Params {
+	if ps == nil {
+		ps = params()
+	}
+	for _, key := range r.keys {
+		*ps = append(*ps, Param{Key: key, Value: queryValue(r.query[key], query[key])})
+	}
+	return ps
+}
+
+// queryValue returns the first required value, or the first value present in
+// the request if any value is accepted.
+func queryValue(want, have []string) string {
+	if len(want) > 0 {
+		return want[0]
+	}
+	if len(have) > 0 {
+		return have[0]
+	}
+	return ""
+}
+
+func sameValues[T ~map[string][]string](a, b T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, av := range a {
+		bv, ok := b[key]
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for _, v := range av {
+			if !contains(bv, v) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
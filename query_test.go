@@ -0,0 +1,104 @@
+package pathmatcher
+
+import (
+	"bytes"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindQuery(t *testing.T) {
+	m := NewHttpMatcher[string]()
+	m.AddQuery("GET", "/api", url.Values{"action": {"list"}}, "list")
+	m.AddQuery("GET", "/api", url.Values{"action": {"list"}, "format": nil}, "list-formatted")
+	m.AddQuery("GET", "/api", url.Values{"action": nil}, "other-action")
+	m.AddQuery("GET", "/items/:id", url.Values{"v": {"2"}}, "item-v2")
+	m.GET("/items/:id", "item")
+	m.AddQuery("POST", "/only", url.Values{"go": nil}, "only")
+
+	tests := []struct {
+		method, path, query string
+		match, value        string
+		params              Params
+	}{
+		{"GET", "/api", "action=list", "/api", "list", Params{{"action", "list"}}},
+		{"GET", "/api", "action=list&format=xml", "/api", "list-formatted", Params{{"action", "list"}, {"format", "xml"}}},
+		{"GET", "/api", "format=xml&action=list&x=y", "/api", "list-formatted", Params{{"action", "list"}, {"format", "xml"}}},
+		{"GET", "/api", "action=delete", "/api", "other-action", Params{{"action", "delete"}}},
+		{"GET", "/api", "action=delete&action=list", "/api", "list", Params{{"action", "list"}}},
+		{"GET", "/api", "", "", "", nil},
+		{"GET", "/api", "format=xml", "", "", nil},
+		{"GET", "/items/7", "v=2", "/items/:id", "item-v2", Params{{"id", "7"}, {"v", "2"}}},
+		{"GET", "/items/7", "v=1", "/items/:id", "item", Params{{"id", "7"}}},
+		{"GET", "/items/7", "", "/items/:id", "item", Params{{"id", "7"}}},
+		{"POST", "/only", "go", "/only", "only", Params{{"go", ""}}},
+		{"POST", "/only", "", "", "", nil},
+	}
+	for _, test := range tests {
+		query, err := url.ParseQuery(test.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		match, value, params, _ := m.FindQuery(test.method, test.path, query)
+		if match != test.match || value != test.value || !reflect.DeepEqual(params, test.params) {
+			t.Errorf("FindQuery(%s, %s, %q) = %q, %q, %+v; want %q, %q, %+v", test.method, test.path, test.query,
+				match, value, params, test.match, test.value, test.params)
+		}
+	}
+
+	// Plain Find only sees unqualified values.
+	if match, value, _, _ := m.Find("GET", "/api"); match != "" || value != "" {
+		t.Errorf("unexpected match for unqualified lookup: %q, %q", match, value)
+	}
+	if _, value, _, _ := m.Find("GET", "/items/1"); value != "item" {
+		t.Errorf("wrong fallback value: %q", value)
+	}
+}
+
+func TestAddQueryConflicts(t *testing.T) {
+	m := NewHttpMatcher[int]()
+	m.GET("/a/:b", 1)
+	m.AddQuery("GET", "/a/:b", url.Values{"x": nil}, 2)
+
+	tests := []func(){
+		func() { m.AddQuery("GET", "/a/:b", url.Values{"x": nil}, 3) },
+		func() { m.AddQuery("GET", "/a/:c", url.Values{"x": nil}, 3) },
+		func() { m.AddQuery("GET", "/a/b", url.Values{"x": nil}, 3) },
+		func() { m.AddQuery("GET", "/a", url.Values{}, 3) },
+		func() { m.AddQuery("FETCH", "/a", url.Values{"x": nil}, 3) },
+		func() { m.GET("/a/:b", 3) },
+	}
+	for i, test := range tests {
+		if recv := catchPanic(test); recv == nil {
+			t.Errorf("no panic for conflicting route %d", i)
+		}
+	}
+}
+
+func TestExportQuery(t *testing.T) {
+	m := NewHttpMatcher[string]()
+	m.GET("/api", "fallback")
+	m.AddQuery("GET", "/api", url.Values{"action": {"list"}}, "list")
+
+	var buf bytes.Buffer
+	if err := WriteJSONL(&buf, m.Export(func(v string) string { return v })); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"method":"GET","path":"/api","name":"fallback"}
+{"method":"GET","path":"/api","name":"list","query":{"action":["list"]}}
+`
+	if buf.String() != want {
+		t.Fatalf("wrong export:\n%s\nexpected:\n%s", buf.String(), want)
+	}
+
+	loaded, err := LoadHttpMatcher(strings.NewReader(want), func(spec RouteSpec) (string, error) {
+		return spec.Name, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, value, _, _ := loaded.FindQuery("GET", "/api", url.Values{"action": {"list"}}); value != "list" {
+		t.Errorf("wrong value after load: %q", value)
+	}
+}
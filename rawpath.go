@@ -0,0 +1,127 @@
+package pathmatcher
+
+import (
+	"net/url"
+	"strings"
+)
+
+// FindEscaped is like Find, but takes an escaped path such as the one returned
+// by url.URL.EscapedPath, so that an encoded slash in "/files/a%2Fb" stays
+// inside a single segment instead of separating two.
+//
+// All other escapes are decoded before the lookup, so patterns are written
+// unescaped as usual. Param values are returned decoded, unless EscapedParams
+// is set, in which case they keep "%2F" and "%25" escaped and nothing else.
+func (m *Matcher[V]) FindEscaped(path string) (match string, value V, params Params, redir bool) {
+	match, value, params, redir = m.Find(unescapeSegments(path))
+	if !m.EscapedParams {
+		unescapeParams(params)
+	}
+	return
+}
+
+// FindURL looks up u.EscapedPath() with FindEscaped if UseRawPath is set, and
+// u.Path with Find otherwise.
+func (m *Matcher[V]) FindURL(u *url.URL) (match string, value V, params Params, redir bool) {
+	if m.UseRawPath {
+		return m.FindEscaped(u.EscapedPath())
+	}
+	return m.Find(u.Path)
+}
+
+// FindEscaped is like Find, but takes an escaped path such as the one returned
+// by url.URL.EscapedPath. See Matcher.FindEscaped.
+func (m *HttpMatcher[V]) FindEscaped(method, path string) (match string, value V, params Params, redir bool) {
+	match, value, params, redir = m.Find(method, unescapeSegments(path))
+	if !m.EscapedParams {
+		unescapeParams(params)
+	}
+	return
+}
+
+// FindURL looks up u.EscapedPath() with FindEscaped if UseRawPath is set, and
+// u.Path with Find otherwise.
+func (m *HttpMatcher[V]) FindURL(method string, u *url.URL) (match string, value V, params Params, redir bool) {
+	if m.UseRawPath {
+		return m.FindEscaped(method, u.EscapedPath())
+	}
+	return m.Find(method, u.Path)
+}
+
+// unescapeSegments decodes the escapes of an escaped path except for "%2F" and
+// "%25", so that segments stay separated by real slashes only and the result
+// can still be decoded unambiguously. Invalid escapes are left as they are. If
+// the path contains no escapes, it is returned without allocating.
+func unescapeSegments(p string) string {
+	i := strings.IndexByte(p, '%')
+	if i < 0 {
+		return p
+	}
+
+	buf := make([]byte, i, len(p))
+	copy(buf, p[:i])
+	for ; i < len(p); i++ {
+		c := p[i]
+		if c == '%' && i+2 < len(p) && ishex(p[i+1]) && ishex(p[i+2]) {
+			b := unhex(p[i+1])<<4 | unhex(p[i+2])
+			if b == '/' || b == '%' {
+				// Normalize to upper case, unescapeParams relies on it
+				buf = append(buf, '%', upper(p[i+1]), upper(p[i+2]))
+			} else {
+				buf = append(buf, b)
+			}
+			i += 2
+			continue
+		}
+		buf = append(buf, c)
+	}
+	return string(buf)
+}
+
+// escapeSegments is the inverse of unescapeSegments: it escapes the segments
+// of p, keeping "%2F" and "%25" escaped, so that the result can be used as
+// the RawPath of a URL.
+func escapeSegments(p string) string {
+	segments := strings.Split(p, "/")
+	for i, seg := range segments {
+		if unescaped, err := url.PathUnescape(seg); err == nil {
+			seg = unescaped
+		}
+		segments[i] = url.PathEscape(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+// unescapeParams decodes the "%2F" and "%25" escapes left in param values by
+// unescapeSegments.
+func unescapeParams(ps Params) {
+	for i := range ps {
+		if strings.IndexByte(ps[i].Value, '%') >= 0 {
+			ps[i].Value = paramUnescaper.Replace(ps[i].Value)
+		}
+	}
+}
+
+var paramUnescaper = strings.NewReplacer("%2F", "/", "%25", "%")
+
+func ishex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+func upper(c byte) byte {
+	if 'a' <= c && c <= 'f' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
@@ -0,0 +1,86 @@
+package pathmatcher
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestUnescapeSegments(t *testing.T) {
+	tests := []struct{ in, out string }{
+		{"/files/a/b", "/files/a/b"},
+		{"/files/a%2Fb", "/files/a%2Fb"},
+		{"/files/a%2fb", "/files/a%2Fb"},
+		{"/files/100%25", "/files/100%25"},
+		{"/h%C3%A9llo/w%20orld", "/héllo/w orld"},
+		{"/bad/%zz/%4", "/bad/%zz/%4"},
+	}
+	for _, test := range tests {
+		if out := unescapeSegments(test.in); out != test.out {
+			t.Errorf("unescapeSegments(%q) = %q, want %q", test.in, out, test.out)
+		}
+	}
+
+	allocs := testing.AllocsPerRun(100, func() { unescapeSegments("/files/a/b") })
+	if allocs > 0 {
+		t.Errorf("unescapeSegments of an unescaped path: %v allocs, want zero", allocs)
+	}
+}
+
+func TestFindEscaped(t *testing.T) {
+	m := NewHttpMatcher[int]()
+	m.GET("/files/:name", 1)
+	m.GET("/files/:name/raw", 2)
+	m.GET("/src/*filepath", 3)
+	m.GET("/héllo/:who", 4)
+
+	tests := []struct {
+		path    string
+		value   int
+		params  Params
+		escaped Params
+	}{
+		{"/files/a%2Fb", 1, Params{{"name", "a/b"}}, Params{{"name", "a%2Fb"}}},
+		{"/files/a%2fb/raw", 2, Params{{"name", "a/b"}}, Params{{"name", "a%2Fb"}}},
+		{"/files/100%25%2F", 1, Params{{"name", "100%/"}}, Params{{"name", "100%25%2F"}}},
+		{"/files/%C3%BCber", 1, Params{{"name", "über"}}, Params{{"name", "über"}}},
+		{"/src/a%2Fb/c%20d", 3, Params{{"filepath", "/a/b/c d"}}, Params{{"filepath", "/a%2Fb/c d"}}},
+		{"/h%C3%A9llo/w%C3%B6rld", 4, Params{{"who", "wörld"}}, Params{{"who", "wörld"}}},
+		{"/files/a/b", 0, nil, nil},
+	}
+	for _, escaped := range []bool{false, true} {
+		m.EscapedParams = escaped
+		for _, test := range tests {
+			_, value, params, _ := m.FindEscaped("GET", test.path)
+			want := test.params
+			if escaped {
+				want = test.escaped
+			}
+			if value != test.value || !reflect.DeepEqual(params, want) {
+				t.Errorf("FindEscaped(%q) with EscapedParams=%t = %d, %+v; want %d, %+v",
+					test.path, escaped, value, params, test.value, want)
+			}
+		}
+	}
+}
+
+func TestFindURL(t *testing.T) {
+	m := NewMatcher[int]()
+	m.Add("/files/:name", 1)
+
+	u, err := url.Parse("/files/a%2Fb")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The decoded path has an extra segment.
+	if _, value, _, _ := m.FindURL(u); value != 0 {
+		t.Errorf("expected no match on the decoded path, got %d", value)
+	}
+
+	m.UseRawPath = true
+	_, value, params, _ := m.FindURL(u)
+	if value != 1 || params.ByName("name") != "a/b" {
+		t.Errorf("wrong match on the raw path: %d, %+v", value, params)
+	}
+}
@@ -0,0 +1,74 @@
+package pathmatcher
+
+import (
+	"sort"
+)
+
+// Reoptimize reorders the children of every node of the tree by the number of
+// hits recorded in stats for the patterns below them, most frequent first.
+// Lookups compare the next path byte against the children of a node in order,
+// so for skewed traffic the hot paths are found after fewer comparisons than
+// with the default order by number of registered routes. Children with equal
+// hits keep that default order.
+//
+// Only patterns of stats without a method are considered. Routes added later
+// are inserted in the default order again. Like Add, Reoptimize is not safe
+// for concurrent use with lookups.
+func (m *Matcher[V]) Reoptimize(stats StatsSnapshot) {
+	m.tree.reorder(stats.hits(""))
+}
+
+// Reoptimize reorders the tree of every method by the hits recorded in stats
+// for that method, see Matcher.Reoptimize.
+func (m *HttpMatcher[V]) Reoptimize(stats StatsSnapshot) {
+	for method, tree := range m.trees {
+		tree.reorder(stats.hits(method))
+	}
+}
+
+// hits returns the hits of the patterns of method by pattern.
+func (s StatsSnapshot) hits(method string) map[string]uint64 {
+	hits := make(map[string]uint64)
+	for _, ps := range s.Patterns {
+		if ps.Method == method {
+			hits[ps.Pattern] = ps.Hits
+		}
+	}
+	return hits
+}
+
+// reorder sorts the static children of n and every node below it by the hits
+// of their subtrees and returns the hits of the subtree of n.
+func (n *node[V]) reorder(hits map[string]uint64) uint64 {
+	var total uint64
+	if n.value != nil {
+		total = hits[n.fullPath]
+	}
+	weights := make([]uint64, len(n.children))
+	for i, child := range n.children {
+		weights[i] = child.reorder(hits)
+		total += weights[i]
+	}
+
+	// The wildcard child of a node is its only child, and there is nothing
+	// to reorder
+	if n.wildChild || len(n.children) < 2 {
+		return total
+	}
+	order := make([]int, len(n.children))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return weights[order[i]] > weights[order[j]]
+	})
+	children := make([]*node[V], len(n.children))
+	indices := make([]byte, len(n.indices))
+	for i, pos := range order {
+		children[i] = n.children[pos]
+		indices[i] = n.indices[pos]
+	}
+	n.children = children
+	n.indices = string(indices)
+	return total
+}
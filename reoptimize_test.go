@@ -0,0 +1,128 @@
+package pathmatcher
+
+import (
+	"fmt"
+	"testing"
+)
+
+// skewedRoutes returns routes under many static prefixes, ordered by the
+// number of routes below them, and a path of the only route under the last
+// prefix, which gets all the traffic. The hot route has a param, so that
+// lookups walk the tree instead of hitting the map of static patterns.
+func skewedRoutes() (routes []string, hot string) {
+	const prefixes = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxy"
+	for i := range prefixes {
+		for j := 0; j < len(prefixes)-i; j++ {
+			routes = append(routes, fmt.Sprintf("/%c/%d", prefixes[i], j))
+		}
+	}
+	routes = append(routes, "/z/:id")
+	return routes, "/z/hot"
+}
+
+func TestReoptimize(t *testing.T) {
+	routes, hot := skewedRoutes()
+	m := NewMatcher[string]()
+	m.Stats = &Stats{}
+	for _, route := range routes {
+		m.Add(route, route)
+	}
+	if m.tree.indices[0] == 'z' {
+		t.Fatal("hot route is already first")
+	}
+
+	for i := 0; i < 10; i++ {
+		m.Find(hot)
+	}
+	m.Find("/b/0")
+	m.Reoptimize(m.Stats.Snapshot())
+
+	if indices := m.tree.indices; indices[:3] != "zbA" || len(indices) != 52 {
+		t.Errorf("wrong order after Reoptimize: %q", indices)
+	}
+	checkIndices(t, m.tree)
+	for _, route := range routes {
+		if match, value, _, _ := m.Find(route); match != route || value != route {
+			t.Errorf("Find(%s) = %q, %q after Reoptimize", route, match, value)
+		}
+	}
+
+	// Routes added afterwards are still found.
+	m.Add("/a/new", "/a/new")
+	if match, _, _, _ := m.Find("/a/new"); match != "/a/new" {
+		t.Errorf("route added after Reoptimize not found")
+	}
+}
+
+func TestHttpMatcherReoptimize(t *testing.T) {
+	m := NewHttpMatcher[int]()
+	m.Stats = &Stats{}
+	m.GET("/a", 1)
+	m.GET("/ab", 2)
+	m.GET("/b", 3)
+	m.POST("/a", 4)
+	m.POST("/b", 5)
+
+	m.Find("GET", "/b")
+	m.Find("POST", "/b")
+	m.Find("POST", "/b")
+	m.Reoptimize(m.Stats.Snapshot())
+
+	for method, want := range map[string]string{"GET": "ba", "POST": "ba"} {
+		if indices := m.trees[method].indices; indices != want {
+			t.Errorf("%s: wrong order %q, want %q", method, indices, want)
+		}
+	}
+	if _, value, _, _ := m.Find("GET", "/ab"); value != 2 {
+		t.Errorf("wrong value %d after Reoptimize", value)
+	}
+}
+
+// checkIndices verifies that every static child is at the position of its
+// first path byte in the indices of its parent.
+func checkIndices[V any](t *testing.T, n *node[V]) {
+	t.Helper()
+	if !n.wildChild {
+		for i, child := range n.children {
+			if child.path[0] != n.indices[i] {
+				t.Errorf("child %q at index %q", child.path, n.indices[i])
+			}
+		}
+	}
+	for _, child := range n.children {
+		checkIndices(t, child)
+	}
+}
+
+func BenchmarkReoptimize(b *testing.B) {
+	routes, hot := skewedRoutes()
+	newMatcher := func() *Matcher[string] {
+		m := NewMatcher[string]()
+		m.Stats = &Stats{}
+		for _, route := range routes {
+			m.Add(route, route)
+		}
+		m.Find(hot)
+		return m
+	}
+
+	b.Run("Default", func(b *testing.B) {
+		m := newMatcher()
+		m.Stats = nil
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			m.Find(hot)
+		}
+	})
+	b.Run("Reoptimized", func(b *testing.B) {
+		m := newMatcher()
+		m.Reoptimize(m.Stats.Snapshot())
+		m.Stats = nil
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			m.Find(hot)
+		}
+	})
+}
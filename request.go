@@ -0,0 +1,167 @@
+package pathmatcher
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Result is the outcome of FindRequest.
+type Result[V any] struct {
+	Method   string // the method looked up, after MethodFallbacks
+	Match    string // the matched pattern, empty if nothing matched
+	Value    V
+	Params   Params
+	Meta     Meta
+	Redirect bool  // a redirect is recommended, see Find
+	Err      error // ErrNotAcceptable or ErrUnsupportedMediaType, see FindQualified
+
+	stats *Stats // the Stats the lookup was recorded in
+}
+
+// Found reports whether a value was found.
+func (r Result[V]) Found() bool {
+	return r.Match != ""
+}
+
+// Info returns the match information to store in a request context.
+func (r Result[V]) Info() MatchInfo {
+	return MatchInfo{Pattern: r.Match, Params: r.Params, Meta: r.Meta}
+}
+
+// Host returns the matcher for requests to host, creating it if needed.
+// FindRequest consults it before m for requests whose Host header, without
+// port and compared case-insensitively, equals host. If it finds nothing for
+// the request, m is tried next.
+//
+// FindRequest looks up the host matcher with the options of m, such as
+// MethodFallbacks, UseRawPath, EscapedParams and Normalizer. The options of
+// the host matcher itself only apply to its own Find methods.
+func (m *HttpMatcher[V]) Host(host string) *HttpMatcher[V] {
+	host = strings.ToLower(host)
+	if hm := m.hosts[host]; hm != nil {
+		return hm
+	}
+	if m.hosts == nil {
+		m.hosts = make(map[string]*HttpMatcher[V])
+	}
+	hm := NewHttpMatcher[V]()
+	m.hosts[host] = hm
+	return hm
+}
+
+// FindRequest looks up the endpoint for r. It applies the configuration of
+// the matcher the same way for every caller:
+//
+//   - Matchers registered with Host for r.Host are tried first, including
+//     their MethodFallbacks.
+//   - The escaped path is matched if UseRawPath is set, see FindURL.
+//   - Qualified values are selected by r.URL.Query() and r.Header, see
+//     FindQualified.
+//   - If nothing matches r.Method, MethodFallbacks is followed.
+func (m *HttpMatcher[V]) FindRequest(r *http.Request) (res Result[V]) {
+	res, hm := m.resolve(r)
+	hm.Stats.record(res.Method, res.Match, res.Redirect)
+	res.stats = hm.Stats
+	return res
+}
+
+// resolve implements FindRequest without recording Stats. It also returns the
+// matcher that produced the result, which is m if nothing was found.
+func (m *HttpMatcher[V]) resolve(r *http.Request) (Result[V], *HttpMatcher[V]) {
+	if hm := m.hosts[hostname(r.Host)]; hm != nil {
+		if res := hm.findRequest(r, m); res.Found() || res.Redirect || res.Err != nil {
+			return res, hm
+		}
+	}
+	return m.findRequest(r, m), m
+}
+
+// findRequest implements FindRequest for the routes of m, with the lookup
+// options of opts.
+func (m *HttpMatcher[V]) findRequest(r *http.Request, opts *HttpMatcher[V]) (res Result[V]) {
+	path := r.URL.Path
+	if opts.UseRawPath {
+		path = unescapeSegments(r.URL.EscapedPath())
+	}
+	// Parsing the query allocates, so only do it if it's needed
+	var query url.Values
+	if m.qualified != nil {
+		query = r.URL.Query()
+	}
+
+	res.Method = r.Method
+	for i := 0; ; i++ {
+		res.Match, res.Value, res.Params, res.Redirect, res.Err = m.lookup(opts.Normalizer, res.Method, path, query, r.Header)
+		if res.Found() || res.Redirect || res.Err != nil {
+			break
+		}
+		fallback, ok := opts.MethodFallbacks[res.Method]
+		if !ok || i == len(opts.MethodFallbacks) {
+			break
+		}
+		res.Method = fallback
+	}
+
+	if res.Found() {
+		if opts.UseRawPath && !opts.EscapedParams {
+			unescapeParams(res.Params)
+		}
+		res.Meta = m.Meta(res.Method, res.Match)
+	} else {
+		res.Method = r.Method
+	}
+	return res
+}
+
+// hostname returns the lower case host without port.
+func hostname(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(host)
+}
+
+// MatchInfo describes the route matched for a request, as stored in its
+// context.
+type MatchInfo struct {
+	Pattern string
+	Params  Params
+	Meta    Meta
+}
+
+type matchInfoKey struct{}
+
+// WithMatchInfo returns a copy of ctx carrying info.
+func WithMatchInfo(ctx context.Context, info MatchInfo) context.Context {
+	return context.WithValue(ctx, matchInfoKey{}, info)
+}
+
+// MatchInfoFromContext returns the match information stored in ctx by
+// WithMatchInfo.
+func MatchInfoFromContext(ctx context.Context) (info MatchInfo, ok bool) {
+	info, ok = ctx.Value(matchInfoKey{}).(MatchInfo)
+	return
+}
+
+// ParamsFromContext returns the params stored in ctx by WithMatchInfo, or nil.
+func ParamsFromContext(ctx context.Context) Params {
+	info, _ := MatchInfoFromContext(ctx)
+	return info.Params
+}
+
+// PatternFromContext returns the matched pattern stored in ctx by
+// WithMatchInfo, or the empty string.
+func PatternFromContext(ctx context.Context) string {
+	info, _ := MatchInfoFromContext(ctx)
+	return info.Pattern
+}
+
+// MetaFromContext returns the route metadata stored in ctx by WithMatchInfo,
+// or nil.
+func MetaFromContext(ctx context.Context) Meta {
+	info, _ := MatchInfoFromContext(ctx)
+	return info.Meta
+}
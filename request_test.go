@@ -0,0 +1,81 @@
+package pathmatcher
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestFindRequest(t *testing.T) {
+	m := NewHttpMatcher[string]()
+	m.MethodFallbacks = map[string]string{"HEAD": "GET"}
+	m.UseRawPath = true
+	m.AddMeta("GET", "/files/:name", "file", Meta{"auth": true})
+	m.GET("/users/:id", "user")
+	m.AddQuery("GET", "/search", map[string][]string{"q": nil}, "search")
+	m.AddHeader("GET", "/doc", http.Header{"Accept": {"application/json"}}, "doc-json")
+	m.Host("API.example.com").GET("/users/:id", "api-user")
+
+	tests := []struct {
+		method, target, host string
+		header               http.Header
+		res                  Result[string]
+	}{
+		{"GET", "/users/1", "", nil, Result[string]{Method: "GET", Match: "/users/:id", Value: "user", Params: Params{{"id", "1"}}}},
+		{"HEAD", "/users/1", "", nil, Result[string]{Method: "GET", Match: "/users/:id", Value: "user", Params: Params{{"id", "1"}}}},
+		{"POST", "/users/1", "", nil, Result[string]{Method: "POST"}},
+		{"GET", "/users/1", "api.example.com:8080", nil, Result[string]{Method: "GET", Match: "/users/:id", Value: "api-user", Params: Params{{"id", "1"}}}},
+		{"HEAD", "/users/1", "api.example.com", nil, Result[string]{Method: "GET", Match: "/users/:id", Value: "api-user", Params: Params{{"id", "1"}}}},
+		{"GET", "/files/a%2Fb", "api.example.com", nil, Result[string]{Method: "GET", Match: "/files/:name", Value: "file", Params: Params{{"name", "a/b"}}, Meta: Meta{"auth": true}}},
+		{"GET", "/search?q=go", "", nil, Result[string]{Method: "GET", Match: "/search", Value: "search", Params: Params{{"q", "go"}}}},
+		{"GET", "/users/1/", "", nil, Result[string]{Method: "GET", Redirect: true}},
+		{"GET", "/doc", "", http.Header{"Accept": {"image/png"}}, Result[string]{Method: "GET", Err: ErrNotAcceptable}},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, test.target, nil)
+		if test.host != "" {
+			r.Host = test.host
+		}
+		if test.header != nil {
+			r.Header = test.header
+		}
+		res := m.FindRequest(r)
+		if !reflect.DeepEqual(res, test.res) {
+			t.Errorf("FindRequest(%s %s) = %+v; want %+v", test.method, test.target, res, test.res)
+		}
+	}
+}
+
+func TestFindRequestFallbackLoop(t *testing.T) {
+	m := NewHttpMatcher[string]()
+	m.MethodFallbacks = map[string]string{"HEAD": "OPTIONS", "OPTIONS": "HEAD"}
+	m.GET("/", "root")
+
+	res := m.FindRequest(httptest.NewRequest("HEAD", "/", nil))
+	if res.Found() || res.Method != "HEAD" {
+		t.Errorf("unexpected result %+v", res)
+	}
+}
+
+func TestMatchInfoContext(t *testing.T) {
+	m := NewHttpMatcher[string]()
+	m.AddMeta("GET", "/users/:id", "user", Meta{"name": "getUser"})
+
+	r := httptest.NewRequest("GET", "/users/1", nil)
+	if info, ok := MatchInfoFromContext(r.Context()); ok {
+		t.Errorf("unexpected match info %+v", info)
+	}
+
+	res := m.FindRequest(r)
+	ctx := WithMatchInfo(r.Context(), res.Info())
+	if ps := ParamsFromContext(ctx); ps.ByName("id") != "1" {
+		t.Errorf("wrong params from context: %+v", ps)
+	}
+	if pattern := PatternFromContext(ctx); pattern != "/users/:id" {
+		t.Errorf("wrong pattern from context: %q", pattern)
+	}
+	if meta := MetaFromContext(ctx); meta["name"] != "getUser" {
+		t.Errorf("wrong meta from context: %+v", meta)
+	}
+}
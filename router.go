@@ -0,0 +1,271 @@
+package pathmatcher
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Middleware wraps a handler, e.g. to add logging, authentication or panic
+// recovery.
+type Middleware func(http.Handler) http.Handler
+
+// Router is an http.Handler dispatching requests to the handlers registered in
+// its HttpMatcher.
+//
+// Middleware is composed when a handler is registered, not per request: the
+// matcher stores each handler already wrapped by the router's global
+// middleware, then the middleware of its groups, then its own. Before the
+// chain is called, the matched route is stored in the request context, so
+// every middleware can read it with MatchInfoFromContext or MetaFromContext.
+type Router struct {
+	RouteGroup
+
+	// Matcher holds the composed handlers. Its options, such as Normalizer,
+	// UseRawPath or MethodFallbacks, configure the lookup of ServeHTTP.
+	Matcher *HttpMatcher[http.Handler]
+
+	// RedirectTrailingSlash redirects requests whose path only matches with
+	// a trailing slash added or removed, or after normalization, to the
+	// matching path. GET requests are redirected with 301 Moved Permanently,
+	// others with 308 Permanent Redirect.
+	RedirectTrailingSlash bool
+
+	// NotFound is called if no route matches. If nil, http.NotFound is used.
+	NotFound http.Handler
+
+	// MethodNotAllowed is called if no route matches the request method, but
+	// another method of the path does. The Allow header is already set. If
+	// nil, the router answers 405 Method Not Allowed.
+	MethodNotAllowed http.Handler
+
+	cors map[string]map[string]*CORS
+}
+
+// RouteGroup registers routes under a common path prefix and middleware. Create
+// one with Router.Group or RouteGroup.Group.
+type RouteGroup struct {
+	router     *Router
+	prefix     string
+	middleware []Middleware
+	cors       *CORS
+	sealed     bool
+}
+
+// NewRouter returns a router with an empty matcher that redirects trailing
+// slashes and answers HEAD requests with GET handlers.
+func NewRouter() *Router {
+	r := &Router{
+		Matcher:               NewHttpMatcher[http.Handler](),
+		RedirectTrailingSlash: true,
+	}
+	r.Matcher.MethodFallbacks = map[string]string{http.MethodHead: http.MethodGet}
+	r.RouteGroup.router = r
+	return r
+}
+
+// Use appends middleware to the group. Since middleware is composed at
+// registration, Use panics once routes or subgroups were added to the group.
+func (g *RouteGroup) Use(middleware ...Middleware) {
+	if g.sealed {
+		panic("middleware must be added before routes and groups under prefix '" + g.prefix + "'")
+	}
+	g.middleware = append(g.middleware, middleware...)
+}
+
+// Group returns a subgroup for routes under prefix, which must not end in a
+// slash, wrapped by middleware after the middleware of g.
+func (g *RouteGroup) Group(prefix string, middleware ...Middleware) *RouteGroup {
+	if prefix != "" && (prefix[0] != '/' || prefix[len(prefix)-1] == '/') {
+		panic("group prefix must begin and not end with '/' in prefix '" + prefix + "'")
+	}
+	g.sealed = true
+	return &RouteGroup{
+		router:     g.router,
+		prefix:     g.prefix + prefix,
+		middleware: append(g.middleware[:len(g.middleware):len(g.middleware)], middleware...),
+		cors:       g.cors,
+	}
+}
+
+// Handle registers handler for method and the path relative to the group,
+// wrapped by the middleware of the group and then by middleware.
+func (g *RouteGroup) Handle(method, path string, handler http.Handler, middleware ...Middleware) {
+	g.HandleMeta(method, path, nil, handler, middleware...)
+}
+
+// HandleMeta is like Handle and attaches meta to the route, see
+// HttpMatcher.AddMeta.
+func (g *RouteGroup) HandleMeta(method, path string, meta Meta, handler http.Handler, middleware ...Middleware) {
+	g.sealed = true
+	g.router.Matcher.AddMeta(method, g.prefix+path, g.wrap(handler, middleware), meta)
+	if g.cors != nil {
+		rt := g.router
+		if rt.cors == nil {
+			rt.cors = make(map[string]map[string]*CORS)
+		}
+		if rt.cors[method] == nil {
+			rt.cors[method] = make(map[string]*CORS)
+		}
+		rt.cors[method][g.prefix+path] = g.cors
+	}
+}
+
+// HandleFunc is like Handle for a handler function.
+func (g *RouteGroup) HandleFunc(method, path string, handler http.HandlerFunc, middleware ...Middleware) {
+	g.Handle(method, path, handler, middleware...)
+}
+
+// wrap composes the middleware of the group and middleware around handler,
+// the first middleware being the outermost. CORS headers are added outside
+// of all middleware, so that they are present on rejected requests too.
+func (g *RouteGroup) wrap(handler http.Handler, middleware []Middleware) http.Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	for i := len(g.middleware) - 1; i >= 0; i-- {
+		handler = g.middleware[i](handler)
+	}
+	if g.cors != nil {
+		handler = g.cors.wrap(handler)
+	}
+	return handler
+}
+
+func (g *RouteGroup) GET(path string, handler http.HandlerFunc) {
+	g.Handle(http.MethodGet, path, handler)
+}
+
+func (g *RouteGroup) HEAD(path string, handler http.HandlerFunc) {
+	g.Handle(http.MethodHead, path, handler)
+}
+
+func (g *RouteGroup) POST(path string, handler http.HandlerFunc) {
+	g.Handle(http.MethodPost, path, handler)
+}
+
+func (g *RouteGroup) PUT(path string, handler http.HandlerFunc) {
+	g.Handle(http.MethodPut, path, handler)
+}
+
+func (g *RouteGroup) PATCH(path string, handler http.HandlerFunc) {
+	g.Handle(http.MethodPatch, path, handler)
+}
+
+func (g *RouteGroup) DELETE(path string, handler http.HandlerFunc) {
+	g.Handle(http.MethodDelete, path, handler)
+}
+
+func (g *RouteGroup) OPTIONS(path string, handler http.HandlerFunc) {
+	g.Handle(http.MethodOptions, path, handler)
+}
+
+// ServeHTTP dispatches the request to the handler of the matched route, see
+// HttpMatcher.FindRequest. OPTIONS requests to a path without an OPTIONS
+// route are answered with 204 No Content and the Allow header, or as CORS
+// preflight requests, see RouteGroup.CORS.
+func (rt *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodOptions && rt.preflight(w, req) {
+		return
+	}
+
+	res := rt.Matcher.FindRequest(req)
+	switch {
+	case res.Found():
+		req = req.WithContext(WithMatchInfo(req.Context(), res.Info()))
+		// Latency goes to the Stats that counted the hit, which is the
+		// host matcher's for routes added to a Host
+		if stats := res.stats; stats != nil {
+			start := time.Now()
+			defer func() { stats.observe(res.Method, res.Match, time.Since(start)) }()
+		}
+		res.Value.ServeHTTP(w, req)
+		return
+	case res.Err == ErrNotAcceptable:
+		http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+		return
+	case res.Err == ErrUnsupportedMediaType:
+		http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+		return
+	case res.Redirect && rt.RedirectTrailingSlash && req.Method != http.MethodConnect:
+		if path, ok := rt.redirectPath(req); ok {
+			code := http.StatusMovedPermanently
+			if req.Method != http.MethodGet {
+				code = http.StatusPermanentRedirect
+			}
+			u := *req.URL
+			if rt.Matcher.UseRawPath {
+				// Keep the escaped slashes that FindRequest matched
+				u.RawPath = escapeSegments(path)
+				u.Path, _ = url.PathUnescape(u.RawPath)
+			} else {
+				u.Path, u.RawPath = path, ""
+			}
+			http.Redirect(w, req, u.String(), code)
+			return
+		}
+	}
+
+	if allow := rt.allowed(req); allow != http.MethodOptions {
+		w.Header().Set("Allow", allow)
+		if req.Method == http.MethodOptions {
+			// Answer OPTIONS requests for existing paths with the allowed
+			// methods, unless a route handles them
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if rt.MethodNotAllowed != nil {
+			rt.MethodNotAllowed.ServeHTTP(w, req)
+		} else {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}
+		return
+	}
+	if rt.NotFound != nil {
+		rt.NotFound.ServeHTTP(w, req)
+	} else {
+		http.NotFound(w, req)
+	}
+}
+
+// matchPath returns the path of req as FindRequest matches it.
+func (rt *Router) matchPath(req *http.Request) string {
+	if rt.Matcher.UseRawPath {
+		return unescapeSegments(req.URL.EscapedPath())
+	}
+	return req.URL.Path
+}
+
+// allowed returns the Allow list for req, from the matcher for its host if
+// that one has routes for the path, see HttpMatcher.Host.
+func (rt *Router) allowed(req *http.Request) string {
+	path := rt.matchPath(req)
+	if hm := rt.Matcher.hosts[hostname(req.Host)]; hm != nil {
+		if allow := hm.Allowed(path); allow != http.MethodOptions {
+			return allow
+		}
+	}
+	return rt.Matcher.Allowed(path)
+}
+
+// redirectPath returns the path the request should be redirected to: the
+// normalized path if normalization changes it, otherwise the path with the
+// trailing slash toggled. Like matchPath, it keeps "%2F" and "%25" escaped if
+// UseRawPath is set.
+func (rt *Router) redirectPath(req *http.Request) (string, bool) {
+	path := rt.matchPath(req)
+	if n := rt.Matcher.Normalizer; n != nil {
+		normalized, changed, err := n.Normalize(path)
+		if err != nil {
+			return "", false
+		}
+		if changed {
+			return normalized, true
+		}
+	}
+	if len(path) > 1 && strings.HasSuffix(path, "/") {
+		return path[:len(path)-1], true
+	}
+	return path + "/", true
+}
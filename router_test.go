@@ -0,0 +1,149 @@
+package pathmatcher
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// tag returns a middleware recording its name, the matched pattern and the
+// route name in the X-Trace response header.
+func tag(name string, wraps *int) Middleware {
+	return func(next http.Handler) http.Handler {
+		*wraps++
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Trace", fmt.Sprintf("%s %s %v", name, PatternFromContext(r.Context()), MetaFromContext(r.Context())["name"]))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestRouterMiddleware(t *testing.T) {
+	var wraps int
+	rt := NewRouter()
+	rt.Use(tag("global", &wraps))
+	rt.GET("/", func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "index") })
+
+	api := rt.Group("/api", tag("api", &wraps))
+	v1 := api.Group("/v1")
+	v1.Use(tag("v1", &wraps))
+	v1.HandleMeta("GET", "/users/:id", Meta{"name": "getUser"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "user ", ParamsFromContext(r.Context()).ByName("id"))
+	}), tag("route", &wraps))
+
+	if wraps != 5 {
+		t.Fatalf("middleware wrapped %d times at registration, want 5", wraps)
+	}
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		rt.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/users/7", nil))
+		if w.Code != http.StatusOK || w.Body.String() != "user 7" {
+			t.Fatalf("wrong response: %d %q", w.Code, w.Body.String())
+		}
+		want := []string{
+			"global /api/v1/users/:id getUser",
+			"api /api/v1/users/:id getUser",
+			"v1 /api/v1/users/:id getUser",
+			"route /api/v1/users/:id getUser",
+		}
+		if trace := w.Header().Values("X-Trace"); strings.Join(trace, "\n") != strings.Join(want, "\n") {
+			t.Errorf("wrong middleware trace:\n%s\nexpected:\n%s", strings.Join(trace, "\n"), strings.Join(want, "\n"))
+		}
+	}
+	if wraps != 5 {
+		t.Errorf("middleware wrapped %d times after serving, want 5", wraps)
+	}
+}
+
+func TestRouterUseAfterRoutes(t *testing.T) {
+	rt := NewRouter()
+	g := rt.Group("/g")
+	g.GET("/a", func(http.ResponseWriter, *http.Request) {})
+
+	tests := []func(){
+		func() { rt.Use(func(h http.Handler) http.Handler { return h }) },
+		func() { g.Use(func(h http.Handler) http.Handler { return h }) },
+		func() { rt.Group("/trailing/") },
+		func() { rt.Group("relative") },
+	}
+	for i, test := range tests {
+		if recv := catchPanic(test); recv == nil {
+			t.Errorf("no panic for test %d", i)
+		}
+	}
+}
+
+func TestRouterServeHTTP(t *testing.T) {
+	rt := NewRouter()
+	ok := func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "ok") }
+	rt.GET("/users/", ok)
+	rt.GET("/doc", ok)
+	rt.POST("/doc", ok)
+	rt.Matcher.AddHeader("GET", "/json", http.Header{"Accept": {"application/json"}}, http.HandlerFunc(ok))
+
+	tests := []struct {
+		method, path string
+		header       http.Header
+		code         int
+		location     string
+		allow        string
+	}{
+		{"GET", "/doc", nil, http.StatusOK, "", ""},
+		{"HEAD", "/doc", nil, http.StatusOK, "", ""},
+		{"GET", "/users", nil, http.StatusMovedPermanently, "/users/", ""},
+		{"POST", "/doc/", nil, http.StatusPermanentRedirect, "/doc", ""},
+		{"DELETE", "/doc", nil, http.StatusMethodNotAllowed, "", "GET, OPTIONS, POST"},
+		{"OPTIONS", "/doc", nil, http.StatusNoContent, "", "GET, OPTIONS, POST"},
+		{"OPTIONS", "/nope", nil, http.StatusNotFound, "", ""},
+		{"GET", "/nope", nil, http.StatusNotFound, "", ""},
+		{"GET", "/json", http.Header{"Accept": {"text/html"}}, http.StatusNotAcceptable, "", ""},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, test.path, nil)
+		if test.header != nil {
+			r.Header = test.header
+		}
+		w := httptest.NewRecorder()
+		rt.ServeHTTP(w, r)
+		if w.Code != test.code || w.Header().Get("Location") != test.location || w.Header().Get("Allow") != test.allow {
+			t.Errorf("%s %s: got %d, Location %q, Allow %q; want %d, %q, %q", test.method, test.path,
+				w.Code, w.Header().Get("Location"), w.Header().Get("Allow"), test.code, test.location, test.allow)
+		}
+	}
+}
+
+func TestRouterRawPathAndHost(t *testing.T) {
+	rt := NewRouter()
+	rt.Matcher.UseRawPath = true
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, ParamsFromContext(r.Context()).ByName("name"))
+	}
+	rt.GET("/files/:name", ok)
+	rt.Matcher.Host("api.example.com").GET("/users/:id", http.HandlerFunc(ok))
+
+	tests := []struct {
+		method, host, path string
+		code               int
+		location, allow    string
+	}{
+		{"GET", "example.com", "/files/a%2Fb", http.StatusOK, "", ""},
+		{"GET", "example.com", "/files/a%2Fb/", http.StatusMovedPermanently, "/files/a%2Fb", ""},
+		{"GET", "example.com", "/files/a%2Fb%20c/", http.StatusMovedPermanently, "/files/a%2Fb%20c", ""},
+		{"DELETE", "api.example.com", "/users/1", http.StatusMethodNotAllowed, "", "GET, OPTIONS"},
+		{"DELETE", "api.example.com", "/files/1", http.StatusMethodNotAllowed, "", "GET, OPTIONS"},
+		{"DELETE", "example.com", "/users/1", http.StatusNotFound, "", ""},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, test.path, nil)
+		r.Host = test.host
+		w := httptest.NewRecorder()
+		rt.ServeHTTP(w, r)
+		if w.Code != test.code || w.Header().Get("Location") != test.location || w.Header().Get("Allow") != test.allow {
+			t.Errorf("%s %s%s: got %d, Location %q, Allow %q; want %d, %q, %q", test.method, test.host, test.path,
+				w.Code, w.Header().Get("Location"), w.Header().Get("Allow"), test.code, test.location, test.allow)
+		}
+	}
+}
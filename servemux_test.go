@@ -0,0 +1,308 @@
+//go:build go1.22
+
+//go:debug httpmuxgo121=0
+
+package pathmatcher
+
+import (
+	"fmt"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+// The differential test below serves the same routes with a Router and with a
+// net/http.ServeMux, using the patterns translated by muxPattern, and compares
+// the responses to generated requests: the pattern and params that handled a
+// request, or the redirect, 404 and 405 responses.
+//
+// Route sets are reduced to the routes both accept. ServeMux can't express
+// wildcards that don't span a whole path segment, like "/user_:name", and it
+// rejects some sets pathmatcher accepts, and vice versa. Pathmatcher never
+// accepts overlapping patterns, so no request can be matched by different
+// patterns of the same set.
+//
+// These intentional differences are reported, but are not failures:
+//
+//   - muxCleanPath: ServeMux redirects paths containing "//", "." or ".."
+//     elements to the cleaned path. Pathmatcher only does so with a
+//     Normalizer, otherwise such paths are matched as is.
+//   - muxTrailingSlash: a Router recommends a redirect when the path only
+//     matches with the trailing slash added or removed. ServeMux only adds the
+//     slash, and only for patterns matching a subtree, like "/src/{path...}".
+//     On the other hand, ServeMux answers 405 Method Not Allowed if the path
+//     with a trailing slash added matches another method.
+//
+// Some differences are normalized away instead of reported: a catch-all param
+// value includes the slash before it, the status codes of redirects differ,
+// and the Allow header of a 405 response lists OPTIONS instead of HEAD.
+// ServeMux matches segments of the escaped path, so "%2F" in a param doesn't
+// end the segment, which pathmatcher only does with UseRawPath set; requests
+// with "%2F" are compared with the responses of a Router with UseRawPath set.
+const (
+	muxCleanPath     = "clean path"
+	muxTrailingSlash = "trailing slash"
+)
+
+// muxPattern translates a pattern to the syntax of net/http.ServeMux:
+// ":name" becomes "{name}", "*name" becomes "{name...}", and a trailing slash
+// is anchored with "{$}" to only match the path itself instead of the whole
+// subtree. It reports false if ServeMux can't express the pattern.
+func muxPattern(pattern string) (string, bool) {
+	segments := strings.Split(pattern[1:], "/")
+	for i, seg := range segments {
+		switch {
+		case seg == "" && i == len(segments)-1:
+			segments[i] = "{$}"
+		case seg == "" || strings.ContainsAny(seg, "{}"):
+			return "", false
+		case seg[0] == ':' || seg[0] == '*':
+			name := seg[1:]
+			if !token.IsIdentifier(name) {
+				return "", false
+			}
+			if seg[0] == '*' {
+				name += "..."
+			}
+			segments[i] = "{" + name + "}"
+		case strings.ContainsAny(seg, ":*"):
+			return "", false
+		}
+	}
+	return "/" + strings.Join(segments, "/"), true
+}
+
+// muxRoutes returns the routes of set both Router and ServeMux accept, and
+// the ServeMux patterns for them.
+func muxRoutes(set []route) (routes []route, patterns []string) {
+	m := NewHttpMatcher[string]()
+	mux := http.NewServeMux()
+	for _, r := range set {
+		pattern, ok := muxPattern(r.path)
+		if !ok {
+			continue
+		}
+		if catchPanic(func() { mux.Handle(r.method+" "+pattern, http.NotFoundHandler()) }) != nil {
+			continue
+		}
+		if catchPanic(func() { m.Add(r.method, r.path, r.path) }) != nil {
+			// A panic may leave the tree half updated, so start over. The
+			// route stays registered with the mux, which only makes it reject
+			// more routes.
+			m = NewHttpMatcher[string]()
+			for _, r := range routes {
+				m.Add(r.method, r.path, r.path)
+			}
+			continue
+		}
+		routes = append(routes, r)
+		patterns = append(patterns, r.method+" "+pattern)
+	}
+	return routes, patterns
+}
+
+// muxRequests returns the requests sent for the route: a path matching it,
+// with empty, escaped and multi-segment params, the trailing slash toggled, an
+// element appended, unclean and upper-case variants, each with the route
+// method and GET, HEAD and DELETE.
+func muxRequests(r route) []route {
+	var paths []string
+	for _, value := range []string{"value", "", "a%2Fb", "a/b"} {
+		var b strings.Builder
+		for i := 0; i < len(r.path); i++ {
+			if c := r.path[i]; c != ':' && c != '*' {
+				b.WriteByte(c)
+				continue
+			}
+			for i+1 < len(r.path) && r.path[i+1] != '/' {
+				i++
+			}
+			b.WriteString(value)
+		}
+		paths = append(paths, b.String())
+	}
+
+	p := paths[0]
+	if strings.HasSuffix(p, "/") {
+		paths = append(paths, p[:len(p)-1])
+	} else {
+		paths = append(paths, p+"/")
+	}
+	paths = append(paths, p+"/extra", "/"+p, "/x/.."+p, p+"/.", strings.ToUpper(p))
+
+	methods := []string{"GET", "HEAD", "DELETE"}
+	if !slices.Contains(methods, r.method) {
+		methods = append(methods, r.method)
+	}
+	var requests []route
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		for _, method := range methods {
+			requests = append(requests, route{method, path})
+		}
+	}
+	return requests
+}
+
+// muxResponse is the outcome of a request, as far as it's compared.
+type muxResponse struct {
+	status   int
+	location string
+	body     string // pattern and params that handled the request
+}
+
+func (r muxResponse) String() string {
+	switch {
+	case r.location != "":
+		return fmt.Sprintf("%d %s", r.status, r.location)
+	case r.body != "":
+		return fmt.Sprintf("%d %s", r.status, r.body)
+	}
+	return fmt.Sprint(r.status)
+}
+
+func serveMux(h http.Handler, method, target string) muxResponse {
+	w := httptest.NewRecorder()
+	// With a host, a path starting with "//" isn't parsed as one
+	h.ServeHTTP(w, httptest.NewRequest(method, "http://example.com"+target, nil))
+	res := muxResponse{status: w.Code}
+	switch w.Code {
+	case http.StatusMovedPermanently, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		res.status = http.StatusMovedPermanently
+		if u, err := url.Parse(w.Header().Get("Location")); err == nil {
+			res.location = u.Path
+		}
+	case http.StatusOK:
+		res.body = w.Body.String()
+	}
+	return res
+}
+
+// cleanMuxPath returns the path ServeMux redirects p to: the cleaned path,
+// keeping a trailing slash.
+func cleanMuxPath(p string) string {
+	cleaned := path.Clean(p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// muxDifference returns which intentional difference explains the different
+// responses of the router rt and the mux to the request, or "".
+func muxDifference(req route, rt *Router, router, mux muxResponse) string {
+	path := req.path
+	switch {
+	case mux.status == http.StatusMovedPermanently && cleanMuxPath(path) != path &&
+		(mux.location == cleanMuxPath(path) || mux.location == cleanMuxPath(path)+"/"):
+		// ServeMux may add the slash in the same redirect
+		return muxCleanPath
+	case router.status == http.StatusMovedPermanently && mux.status != http.StatusOK &&
+		(router.location == path+"/" || router.location+"/" == path):
+		return muxTrailingSlash
+	case router.status == http.StatusNotFound && mux.status == http.StatusMethodNotAllowed &&
+		!strings.HasSuffix(path, "/") &&
+		serveMux(rt, req.method, path+"/").status == http.StatusMethodNotAllowed:
+		// The path with the slash added only matches other methods
+		return muxTrailingSlash
+	}
+	return ""
+}
+
+func TestServeMuxDifferential(t *testing.T) {
+	sets := append(routeSets[:len(routeSets):len(routeSets)], struct {
+		name, file string
+		routes     []route
+	}{name: "Tree"})
+	for _, path := range freezeRoutes {
+		sets[len(sets)-1].routes = append(sets[len(sets)-1].routes, route{"GET", path})
+	}
+
+	for _, set := range sets {
+		t.Run(set.name, func(t *testing.T) {
+			routes, patterns := muxRoutes(set.routes)
+			rt, raw := NewRouter(), NewRouter()
+			raw.Matcher.UseRawPath = true
+			mux := http.NewServeMux()
+			for i, r := range routes {
+				// Both handlers write the pattern and params that matched
+				pattern, catchAll := r.path, ""
+				if i := strings.LastIndex(pattern, "/*"); i >= 0 {
+					catchAll = pattern[i+2:]
+				}
+				handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					ps := append(Params(nil), ParamsFromContext(req.Context())...)
+					for i := range ps {
+						if ps[i].Key == catchAll {
+							ps[i].Value = ps[i].Value[1:]
+						}
+					}
+					fmt.Fprint(w, pattern, " ", ps)
+				})
+				rt.Handle(r.method, pattern, handler)
+				raw.Handle(r.method, pattern, handler)
+
+				var names []string
+				for _, seg := range strings.Split(pattern, "/") {
+					if seg != "" && (seg[0] == ':' || seg[0] == '*') {
+						names = append(names, seg[1:])
+					}
+				}
+				mux.HandleFunc(patterns[i], func(w http.ResponseWriter, req *http.Request) {
+					var ps Params
+					for _, name := range names {
+						ps = append(ps, Param{Key: name, Value: req.PathValue(name)})
+					}
+					fmt.Fprint(w, pattern, " ", ps)
+				})
+			}
+
+			var requests []route
+			for _, r := range routes {
+				requests = append(requests, muxRequests(r)...)
+			}
+			if set.file != "" {
+				requests = append(requests, loadRequests(t, set.file)...)
+			}
+
+			differences := make(map[string]int)
+			var agreed, clean, cleanAgreed int
+			for _, req := range requests {
+				rt := rt
+				if strings.Contains(req.path, "%2F") {
+					rt = raw
+				}
+				router, mux := serveMux(rt, req.method, req.path), serveMux(mux, req.method, req.path)
+				if cleanMuxPath(req.path) == req.path {
+					clean++
+					if router == mux {
+						cleanAgreed++
+					}
+				}
+				if router == mux {
+					agreed++
+					continue
+				}
+				if diff := muxDifference(req, rt, router, mux); diff != "" {
+					differences[diff]++
+					continue
+				}
+				t.Errorf("%s %s: Router %v, ServeMux %v", req.method, req.path, router, mux)
+			}
+			// Only trailing slash differences are expected for clean paths
+			if cleanAgreed < clean*4/5 {
+				t.Errorf("only %d of %d responses to clean paths agree", cleanAgreed, clean)
+			}
+			t.Logf("%d of %d routes translated, %d of %d responses agree (%d of %d for clean paths), intentional differences: %v",
+				len(routes), len(set.routes), agreed, len(requests), cleanAgreed, clean, differences)
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package pathmatcher
+
+import (
+	"bufio"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats counts lookups per matched pattern. Set it as the Stats field of a
+// Matcher or HttpMatcher to enable instrumentation; a nil Stats costs a single
+// check per lookup. Counters are keyed by pattern rather than by path, so the
+// number of series is bounded by the number of routes.
+//
+// Stats is safe for concurrent use and may be shared between matchers.
+type Stats struct {
+	patterns  sync.Map // statsKey -> *patternStats
+	misses    atomic.Uint64
+	redirects atomic.Uint64
+}
+
+type statsKey struct {
+	method, pattern string
+}
+
+type patternStats struct {
+	hits    atomic.Uint64
+	latency [len(LatencyBuckets) + 1]atomic.Uint64
+	sum     atomic.Int64
+}
+
+// LatencyBuckets are the upper bounds of the handler latency histogram
+// recorded by Router.
+var LatencyBuckets = [...]time.Duration{
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+func (s *Stats) pattern(method, pattern string) *patternStats {
+	key := statsKey{method, pattern}
+	if ps, ok := s.patterns.Load(key); ok {
+		return ps.(*patternStats)
+	}
+	ps, _ := s.patterns.LoadOrStore(key, &patternStats{})
+	return ps.(*patternStats)
+}
+
+// record counts the outcome of a lookup. It does nothing if s is nil.
+func (s *Stats) record(method, match string, redir bool) {
+	switch {
+	case s == nil:
+	case match != "":
+		s.pattern(method, match).hits.Add(1)
+	case redir:
+		s.redirects.Add(1)
+	default:
+		s.misses.Add(1)
+	}
+}
+
+// observe adds the duration of a request handled by pattern to its latency
+// histogram. It does nothing if s is nil.
+func (s *Stats) observe(method, pattern string, d time.Duration) {
+	if s == nil {
+		return
+	}
+	ps := s.pattern(method, pattern)
+	i := sort.Search(len(LatencyBuckets), func(i int) bool { return d <= LatencyBuckets[i] })
+	ps.latency[i].Add(1)
+	ps.sum.Add(int64(d))
+}
+
+// StatsSnapshot is a point-in-time copy of Stats.
+type StatsSnapshot struct {
+	Misses    uint64         // lookups that matched nothing
+	Redirects uint64         // lookups that only recommended a redirect
+	Patterns  []PatternStats // ordered by method and pattern
+}
+
+// PatternStats holds the counters of a single pattern.
+type PatternStats struct {
+	Method  string // empty for a plain Matcher
+	Pattern string
+	Hits    uint64
+
+	// Latency counts the requests handled by Router per bucket: Latency[i]
+	// those up to LatencyBuckets[i], the last one those above all buckets.
+	Latency      [len(LatencyBuckets) + 1]uint64
+	LatencyCount uint64
+	LatencySum   time.Duration
+}
+
+// Snapshot returns the current counters.
+func (s *Stats) Snapshot() StatsSnapshot {
+	snap := StatsSnapshot{
+		Misses:    s.misses.Load(),
+		Redirects: s.redirects.Load(),
+	}
+	s.patterns.Range(func(k, v any) bool {
+		key, ps := k.(statsKey), v.(*patternStats)
+		stats := PatternStats{
+			Method:     key.method,
+			Pattern:    key.pattern,
+			Hits:       ps.hits.Load(),
+			LatencySum: time.Duration(ps.sum.Load()),
+		}
+		for i := range ps.latency {
+			stats.Latency[i] = ps.latency[i].Load()
+			stats.LatencyCount += stats.Latency[i]
+		}
+		snap.Patterns = append(snap.Patterns, stats)
+		return true
+	})
+	sort.Slice(snap.Patterns, func(i, j int) bool {
+		a, b := snap.Patterns[i], snap.Patterns[j]
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		return a.Pattern < b.Pattern
+	})
+	return snap
+}
+
+// Publish exports the snapshot of s as the expvar variable name, served as
+// JSON by expvar.Handler. Like expvar.Publish, it panics if name is already
+// in use.
+func (s *Stats) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() any { return s.Snapshot() }))
+}
+
+// WritePrometheus writes the snapshot of s in the Prometheus text exposition
+// format: the counters pathmatcher_hits_total, pathmatcher_misses_total and
+// pathmatcher_redirects_total, and the histogram
+// pathmatcher_handler_duration_seconds.
+func (s *Stats) WritePrometheus(w io.Writer) error {
+	snap := s.Snapshot()
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "# HELP pathmatcher_hits_total Lookups that matched the pattern.")
+	fmt.Fprintln(bw, "# TYPE pathmatcher_hits_total counter")
+	for _, ps := range snap.Patterns {
+		fmt.Fprintf(bw, "pathmatcher_hits_total{%s} %d\n", ps.labels(), ps.Hits)
+	}
+	fmt.Fprintln(bw, "# HELP pathmatcher_misses_total Lookups that matched nothing.")
+	fmt.Fprintln(bw, "# TYPE pathmatcher_misses_total counter")
+	fmt.Fprintf(bw, "pathmatcher_misses_total %d\n", snap.Misses)
+	fmt.Fprintln(bw, "# HELP pathmatcher_redirects_total Lookups that recommended a redirect.")
+	fmt.Fprintln(bw, "# TYPE pathmatcher_redirects_total counter")
+	fmt.Fprintf(bw, "pathmatcher_redirects_total %d\n", snap.Redirects)
+
+	fmt.Fprintln(bw, "# HELP pathmatcher_handler_duration_seconds Time spent in the handler of the pattern.")
+	fmt.Fprintln(bw, "# TYPE pathmatcher_handler_duration_seconds histogram")
+	for _, ps := range snap.Patterns {
+		if ps.LatencyCount == 0 {
+			continue
+		}
+		labels := ps.labels()
+		var cumulative uint64
+		for i, bound := range LatencyBuckets {
+			cumulative += ps.Latency[i]
+			fmt.Fprintf(bw, "pathmatcher_handler_duration_seconds_bucket{%s,le=%q} %d\n",
+				labels, strconv.FormatFloat(bound.Seconds(), 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(bw, "pathmatcher_handler_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, ps.LatencyCount)
+		fmt.Fprintf(bw, "pathmatcher_handler_duration_seconds_sum{%s} %s\n",
+			labels, strconv.FormatFloat(ps.LatencySum.Seconds(), 'g', -1, 64))
+		fmt.Fprintf(bw, "pathmatcher_handler_duration_seconds_count{%s} %d\n", labels, ps.LatencyCount)
+	}
+	return bw.Flush()
+}
+
+func (ps PatternStats) labels() string {
+	pattern := `pattern="` + escapeLabel(ps.Pattern) + `"`
+	if ps.Method == "" {
+		return pattern
+	}
+	return `method="` + escapeLabel(ps.Method) + `",` + pattern
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+// Handler returns a handler serving the snapshot of s in the Prometheus text
+// exposition format, see WritePrometheus.
+func (s *Stats) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		s.WritePrometheus(w)
+	})
+}
@@ -0,0 +1,133 @@
+package pathmatcher
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatcherStats(t *testing.T) {
+	m := NewMatcher[int]()
+	m.Stats = &Stats{}
+	m.Add("/users/:id", 1)
+	m.Add("/about/", 2)
+
+	for _, path := range []string{"/users/1", "/users/2", "/users/3", "/about/", "/about", "/nope"} {
+		m.Find(path)
+	}
+
+	snap := m.Stats.Snapshot()
+	if snap.Misses != 1 || snap.Redirects != 1 {
+		t.Errorf("wrong misses %d or redirects %d", snap.Misses, snap.Redirects)
+	}
+	if len(snap.Patterns) != 2 ||
+		snap.Patterns[0].Pattern != "/about/" || snap.Patterns[0].Hits != 1 ||
+		snap.Patterns[1].Pattern != "/users/:id" || snap.Patterns[1].Hits != 3 {
+		t.Errorf("wrong pattern stats: %+v", snap.Patterns)
+	}
+}
+
+func TestHttpMatcherStats(t *testing.T) {
+	m := NewHttpMatcher[int]()
+	m.Stats = &Stats{}
+	m.MethodFallbacks = map[string]string{"HEAD": "GET"}
+	m.GET("/a", 1)
+	m.POST("/a", 2)
+
+	m.Find("GET", "/a")
+	m.FindQuery("POST", "/a", nil)
+	m.FindRequest(httptest.NewRequest("HEAD", "/a", nil))
+	m.Find("PUT", "/a")
+
+	snap := m.Stats.Snapshot()
+	want := []PatternStats{
+		{Method: "GET", Pattern: "/a", Hits: 2},
+		{Method: "POST", Pattern: "/a", Hits: 1},
+	}
+	if snap.Misses != 1 || len(snap.Patterns) != len(want) {
+		t.Fatalf("wrong snapshot: %+v", snap)
+	}
+	for i := range want {
+		if snap.Patterns[i] != want[i] {
+			t.Errorf("wrong stats %+v, want %+v", snap.Patterns[i], want[i])
+		}
+	}
+}
+
+func TestRouterStats(t *testing.T) {
+	rt := NewRouter()
+	rt.Matcher.Stats = &Stats{}
+	rt.GET("/users/:id", func(w http.ResponseWriter, r *http.Request) {})
+	rt.GET("/slow", func(w http.ResponseWriter, r *http.Request) { time.Sleep(2 * time.Millisecond) })
+	api := rt.Group("/api")
+	api.CORS(CORS{AllowedOrigins: []string{"*"}})
+	api.GET("/items", func(w http.ResponseWriter, r *http.Request) {})
+	host := rt.Matcher.Host("api.example.com")
+	host.Stats = &Stats{}
+	host.GET("/hosted", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, path := range []string{"/users/1", "/users/2", "/slow", "/missing"} {
+		rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	// Preflight requests are not lookups of a route
+	req := httptest.NewRequest("OPTIONS", "/api/items", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	rt.ServeHTTP(httptest.NewRecorder(), req)
+	// Hits and latency of host routes go to the host's Stats
+	req = httptest.NewRequest("GET", "/hosted", nil)
+	req.Host = "api.example.com"
+	rt.ServeHTTP(httptest.NewRecorder(), req)
+
+	if snap := host.Stats.Snapshot(); len(snap.Patterns) != 1 || snap.Patterns[0].Hits != 1 || snap.Patterns[0].LatencyCount != 1 {
+		t.Errorf("wrong host stats %+v", snap)
+	}
+
+	var buf strings.Builder
+	if err := rt.Matcher.Stats.WritePrometheus(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		`pathmatcher_hits_total{method="GET",pattern="/users/:id"} 2`,
+		`pathmatcher_hits_total{method="GET",pattern="/slow"} 1`,
+		`pathmatcher_misses_total 1`,
+		`pathmatcher_redirects_total 0`,
+		`pathmatcher_handler_duration_seconds_bucket{method="GET",pattern="/slow",le="0.001"} 0`,
+		`pathmatcher_handler_duration_seconds_bucket{method="GET",pattern="/slow",le="+Inf"} 1`,
+		`pathmatcher_handler_duration_seconds_bucket{method="GET",pattern="/users/:id",le="+Inf"} 2`,
+		`pathmatcher_handler_duration_seconds_count{method="GET",pattern="/users/:id"} 2`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("missing line %s in:\n%s", line, out)
+		}
+	}
+	if strings.Contains(out, `pattern="/api/items"`) || strings.Contains(out, `pattern="/hosted"`) {
+		t.Errorf("unexpected preflight or host stats in:\n%s", out)
+	}
+
+	w := httptest.NewRecorder()
+	rt.Matcher.Stats.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") || w.Body.String() != out {
+		t.Errorf("handler served %q:\n%s", w.Header().Get("Content-Type"), w.Body.String())
+	}
+}
+
+func TestStatsPublish(t *testing.T) {
+	s := &Stats{}
+	s.record("GET", "/a\"b", false)
+	s.Publish("pathmatcher_test_stats")
+	v := expvar.Get("pathmatcher_test_stats")
+	if v == nil || !strings.Contains(v.String(), `"Pattern":"/a\"b"`) {
+		t.Errorf("wrong expvar: %v", v)
+	}
+
+	var buf strings.Builder
+	s.WritePrometheus(&buf)
+	if !strings.Contains(buf.String(), `pattern="/a\"b"`) {
+		t.Errorf("pattern not escaped:\n%s", buf.String())
+	}
+}
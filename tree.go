@@ -75,6 +75,15 @@ type node[V any] struct {
 	value     *V
 }
 
+// walk calls fn for n and every node below it in depth-first order, parents
+// before children and children in index order.
+func (n *node[V]) walk(fn func(n *node[V])) {
+	fn(n)
+	for _, child := range n.children {
+		child.walk(fn)
+	}
+}
+
 // Increments priority of the given child and reorders if necessary
 func (n *node[V]) incrementChildPrio(pos int) int {
 	cs := n.children
@@ -538,8 +547,10 @@ walk: // Outer loop for walking the tree
 					// Find rune start.
 					// Runes are up to 4 byte long,
 					// -4 would definitely be another rune.
+					// The node path may be empty, e.g. before a catch-all,
+					// so off = 0 must be checked as well.
 					var off int
-					for max := min(npLen, 3); off < max; off++ {
+					for max := min(npLen, 3); off <= max; off++ {
 						if i := npLen - off; utf8.RuneStart(oldPath[i]) {
 							// read rune from cached path
 							rv, _ = utf8.DecodeRuneInString(oldPath[i:])
@@ -597,6 +608,9 @@ walk: // Outer loop for walking the tree
 				return nil
 			}
 
+			// A wildcard starts a new rune, forget the bytes of the last one
+			rb = [4]byte{}
+
 			n = n.children[0]
 			switch n.nType {
 			case param: